@@ -0,0 +1,143 @@
+package apipayload
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/checkconfig"
+	"github.com/cf-platform-eng/tileinspect/metadata"
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	tileinspect.TileConfig
+	ConfigFilePath string `long:"config" short:"c" description:"path to config file" required:"true"`
+	MetadataCmd    tileinspect.MetadataCmd
+}
+
+// PayloadProperty is a property in the request body of PUT /api/v0/staged/products/:guid/properties
+type PayloadProperty struct {
+	Value          interface{} `json:"value"`
+	SelectedOption string      `json:"selected_option,omitempty"`
+}
+
+type Payload struct {
+	Properties map[string]*PayloadProperty `json:"properties"`
+}
+
+// wrapSecret makes sure the value of a secret is in the {"secret": ...} form that the API expects
+func wrapSecret(value interface{}) interface{} {
+	if _, ok := value.(map[string]interface{}); ok {
+		return value
+	}
+	return map[string]interface{}{"secret": value}
+}
+
+func collectionValue(value interface{}, blueprints []tileinspect.TileProperty) interface{} {
+	items, ok := value.([]interface{})
+	if !ok {
+		return value
+	}
+
+	var result []interface{}
+	for _, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			result = append(result, item)
+			continue
+		}
+
+		values := make(map[string]interface{})
+		for name, field := range fields {
+			values[name] = field
+		}
+		for _, blueprint := range blueprints {
+			if field, ok := values[blueprint.Name]; ok && blueprint.Type == "secret" {
+				values[blueprint.Name] = wrapSecret(field)
+			}
+		}
+		result = append(result, values)
+	}
+	return result
+}
+
+func payloadProperty(property tileinspect.TileProperty, value interface{}) *PayloadProperty {
+	switch property.Type {
+	case "secret":
+		return &PayloadProperty{Value: wrapSecret(value)}
+	case "collection":
+		return &PayloadProperty{Value: collectionValue(value, property.PropertyBlueprints)}
+	case "selector":
+		for _, option := range property.ChildProperties {
+			if value == option.SelectValue {
+				return &PayloadProperty{Value: value, SelectedOption: option.Name}
+			}
+		}
+	}
+	return &PayloadProperty{Value: value}
+}
+
+func MakePayload(config *tileinspect.ConfigFile, tileProperties *tileinspect.TileProperties) *Payload {
+	blueprints := tileProperties.Blueprints()
+
+	payload := &Payload{
+		Properties: make(map[string]*PayloadProperty),
+	}
+	for propertyKey, property := range config.ProductProperties {
+		if property == nil {
+			continue
+		}
+		payload.Properties[propertyKey] = payloadProperty(blueprints[propertyKey], property.Value)
+	}
+	return payload
+}
+
+func (cmd *Config) ToPayload() (*Payload, error) {
+	config, err := checkconfig.LoadConfigFile(cmd.ConfigFilePath)
+	if err != nil {
+		return nil, err
+	}
+	if config.ProductProperties == nil {
+		return nil, errors.New(`the config file is missing a "product-properties" section`)
+	}
+
+	tileProperties := &tileinspect.TileProperties{}
+	err = cmd.MetadataCmd.LoadMetadata(tileProperties)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load metadata from the tile")
+	}
+
+	check := &checkconfig.Config{}
+	errs := check.CompareProperties(config, tileProperties)
+	if len(errs) > 0 {
+		return nil, errors.Errorf("the config file is not valid for this tile:\n%s\n", checkconfig.JoinErrors(errs))
+	}
+
+	return MakePayload(config, tileProperties), nil
+}
+
+func (cmd *Config) WritePayload(out io.Writer) error {
+	payload, err := cmd.ToPayload()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to print the API payload")
+	}
+	return nil
+}
+
+func (cmd *Config) Execute(args []string) error {
+	cmd.MetadataCmd = &metadata.Config{
+		TileConfig: tileinspect.TileConfig{
+			Tile: cmd.Tile,
+		},
+	}
+	return cmd.WritePayload(os.Stdout)
+}
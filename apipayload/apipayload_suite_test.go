@@ -0,0 +1,13 @@
+package apipayload_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAPIPayload(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "APIPayload Suite")
+}
@@ -0,0 +1,198 @@
+package apipayload_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect/apipayload"
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("WritePayload", func() {
+	var (
+		buffer      *Buffer
+		cmd         *apipayload.Config
+		metadataCmd *tileinspectfakes.FakeMetadataCmd
+	)
+
+	writeConfigFile := func(contents string) {
+		err := os.WriteFile(cmd.ConfigFilePath, []byte(contents), 0644)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		buffer = NewBuffer()
+		metadataCmd = &tileinspectfakes.FakeMetadataCmd{}
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			name: product
+			property_blueprints:
+			  - name: hostname
+			    type: string
+			    configurable: true
+			  - name: admin-password
+			    type: secret
+			    configurable: true
+			  - name: tls
+			    type: rsa_cert_credentials
+			    configurable: true
+			  - name: continent
+			    type: selector
+			    configurable: true
+			    default: North America
+			    option_templates:
+			      - name: north-america
+			        select_value: North America
+			        property_blueprints:
+			          - name: city
+			            type: string
+			            configurable: true
+			            default: Toronto
+			  - name: users
+			    type: collection
+			    configurable: true
+			    optional: true
+			    property_blueprints:
+			      - name: name
+			        type: string
+			        configurable: true
+			      - name: password
+			        type: secret
+			        configurable: true
+			`)), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+
+		cmd = &apipayload.Config{
+			ConfigFilePath: filepath.Join(GinkgoT().TempDir(), "config.yml"),
+			MetadataCmd:    metadataCmd,
+		}
+
+		writeConfigFile(heredoc.Doc(`
+			product-name: product
+			product-properties:
+			  .properties.hostname:
+			    type: string
+			    value: example.com
+			  .properties.admin-password:
+			    type: secret
+			    value:
+			      secret: pa55word
+			  .properties.tls:
+			    type: rsa_cert_credentials
+			    value:
+			      cert_pem: CERT
+			      private_key_pem: KEY
+			  .properties.continent:
+			    type: selector
+			    value: North America
+			  .properties.users:
+			    type: collection
+			    value:
+			      - name: alice
+			        password: hunter2
+			      - name: bob
+			        password:
+			          secret: swordfish
+			`))
+	})
+
+	AfterEach(func() {
+		err := buffer.Close()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("writes the request body for the staged properties", func() {
+		err := cmd.WritePayload(buffer)
+		Expect(err).ToNot(HaveOccurred())
+
+		var payload map[string]interface{}
+		err = json.Unmarshal(buffer.Contents(), &payload)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(payload).To(Equal(map[string]interface{}{
+			"properties": map[string]interface{}{
+				".properties.hostname": map[string]interface{}{"value": "example.com"},
+				".properties.admin-password": map[string]interface{}{
+					"value": map[string]interface{}{"secret": "pa55word"},
+				},
+				".properties.tls": map[string]interface{}{
+					"value": map[string]interface{}{"cert_pem": "CERT", "private_key_pem": "KEY"},
+				},
+				".properties.continent": map[string]interface{}{
+					"value":           "North America",
+					"selected_option": "north-america",
+				},
+				".properties.users": map[string]interface{}{
+					"value": []interface{}{
+						map[string]interface{}{"name": "alice", "password": map[string]interface{}{"secret": "hunter2"}},
+						map[string]interface{}{"name": "bob", "password": map[string]interface{}{"secret": "swordfish"}},
+					},
+				},
+			},
+		}))
+	})
+
+	Context("the config file is not valid for the tile", func() {
+		BeforeEach(func() {
+			writeConfigFile(heredoc.Doc(`
+				product-name: product
+				product-properties:
+				  .properties.hostname:
+				    value: example.com
+				`))
+		})
+
+		It("returns an error", func() {
+			err := cmd.WritePayload(buffer)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("the config file is not valid for this tile:\n"))
+			Expect(err.Error()).To(ContainSubstring("the config file is missing a required property (.properties.admin-password)"))
+		})
+	})
+
+	Context("the config file has no product-properties", func() {
+		BeforeEach(func() {
+			writeConfigFile("product-name: product\n")
+		})
+
+		It("returns an error", func() {
+			err := cmd.WritePayload(buffer)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`the config file is missing a "product-properties" section`))
+		})
+	})
+
+	Context("the config file does not exist", func() {
+		BeforeEach(func() {
+			cmd.ConfigFilePath = "/does/not/exist.yml"
+		})
+
+		It("returns an error", func() {
+			err := cmd.WritePayload(buffer)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("failed to read the config file: /does/not/exist.yml"))
+		})
+	})
+
+	Context("metadata fails to load", func() {
+		BeforeEach(func() {
+			metadataCmd.LoadMetadataReturns(errors.New("metadata-error"))
+		})
+
+		It("returns an error", func() {
+			err := cmd.WritePayload(buffer)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to load metadata from the tile: metadata-error"))
+		})
+	})
+})
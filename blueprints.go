@@ -0,0 +1,55 @@
+package tileinspect
+
+// SelectorOption is a selector option that property blueprints are nested in
+type SelectorOption struct {
+	SelectorKey string
+	Selector    TileProperty
+	Option      TileProperties
+}
+
+func walkBlueprints(propertyPrefix string, blueprints []TileProperty, options []SelectorOption, visit func(propertyKey string, property TileProperty, options []SelectorOption)) {
+	for _, property := range blueprints {
+		propertyKey := propertyPrefix + "." + property.Name
+		visit(propertyKey, property, options)
+
+		for _, option := range property.ChildProperties {
+			optionPath := append(append([]SelectorOption{}, options...), SelectorOption{SelectorKey: propertyKey, Selector: property, Option: option})
+			walkBlueprints(propertyKey+"."+option.Name, option.PropertyBlueprints, optionPath, visit)
+		}
+	}
+}
+
+// WalkBlueprints calls visit for the property blueprints of the tile and its job types, including the blueprints in selector options.
+// options are the selector options the blueprint is nested in, outermost first.
+func (t *TileProperties) WalkBlueprints(visit func(propertyKey string, property TileProperty, options []SelectorOption)) {
+	walkBlueprints(".properties", t.PropertyBlueprints, nil, visit)
+	for _, jobType := range t.JobTypes {
+		walkBlueprints("."+jobType.Name, jobType.PropertyBlueprints, nil, visit)
+	}
+}
+
+// Blueprints returns the property blueprint of every property key of the tile
+func (t *TileProperties) Blueprints() map[string]TileProperty {
+	blueprints := make(map[string]TileProperty)
+	t.WalkBlueprints(func(propertyKey string, property TileProperty, options []SelectorOption) {
+		blueprints[propertyKey] = property
+	})
+	return blueprints
+}
+
+func collectPropertyInputs(inputs []PropertyInput, result map[string]PropertyInput) {
+	for _, input := range inputs {
+		result[input.Reference] = input
+		collectPropertyInputs(input.PropertyInputs, result)
+		collectPropertyInputs(input.SelectorPropertyInputs, result)
+	}
+}
+
+// PropertyInputs returns the form input of every property key in the forms of the tile, with its label and description
+func (t *TileProperties) PropertyInputs() map[string]PropertyInput {
+	inputs := make(map[string]PropertyInput)
+	for _, formType := range t.FormTypes {
+		collectPropertyInputs(formType.PropertyInputs, inputs)
+	}
+	return inputs
+}
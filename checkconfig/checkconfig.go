@@ -19,6 +19,33 @@ type Config struct {
 	ConfigFilePath string `long:"config" short:"c" description:"path to config file" required:"true"`
 }
 
+// PropertyError is a problem with a single property in the config file
+type PropertyError struct {
+	Key string
+	// Missing is true when the property needs a value that the config file does not have
+	Missing bool
+	message string
+}
+
+func (e *PropertyError) Error() string {
+	return e.message
+}
+
+func propertyErrorf(key string, format string, args ...interface{}) error {
+	return &PropertyError{
+		Key:     key,
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+func missingPropertyErrorf(key string, format string, args ...interface{}) error {
+	return &PropertyError{
+		Key:     key,
+		Missing: true,
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
 func stringInSlice(a string, list []string) bool {
 	for _, b := range list {
 		if b == a {
@@ -35,7 +62,7 @@ func checkCollectionProperties(checkForRequiredProperties bool, propertyPrefix s
 	if len(*configValues) == 0 && checkForRequiredProperties {
 		for _, prop := range *tileProperties {
 			if prop.Configurable && !prop.Optional && prop.Default == nil {
-				errs = append(errs, fmt.Errorf("collection (%s) is missing required property %s", propertyPrefix, prop.Name))
+				errs = append(errs, missingPropertyErrorf(propertyPrefix, "collection (%s) is missing required property %s", propertyPrefix, prop.Name))
 			}
 		}
 	} else {
@@ -44,16 +71,16 @@ func checkCollectionProperties(checkForRequiredProperties bool, propertyPrefix s
 				for _, prop := range *tileProperties {
 					if _, ok := value[prop.Name]; ok {
 						if !prop.Configurable {
-							errs = append(errs, fmt.Errorf("collection (%s) contains unconfigurable property %s", propertyPrefix, prop.Name))
+							errs = append(errs, propertyErrorf(propertyPrefix, "collection (%s) contains unconfigurable property %s", propertyPrefix, prop.Name))
 						}
 					} else {
 						if prop.Configurable && !prop.Optional && prop.Default == nil {
-							errs = append(errs, fmt.Errorf("collection (%s) is missing required property %s", propertyPrefix, prop.Name))
+							errs = append(errs, missingPropertyErrorf(propertyPrefix, "collection (%s) is missing required property %s", propertyPrefix, prop.Name))
 						}
 					}
 				}
 			} else {
-				errs = append(errs, fmt.Errorf("collection (%s) contains invalid item %v", propertyPrefix, valueInterface))
+				errs = append(errs, propertyErrorf(propertyPrefix, "collection (%s) contains invalid item %v", propertyPrefix, valueInterface))
 			}
 		}
 	}
@@ -71,7 +98,7 @@ func checkTileProperties(checkForRequiredProperties bool, propertyPrefix string,
 		hasValue := configValues[propertyKey] != nil
 
 		if hasValue && !property.Configurable {
-			errs = append(errs, fmt.Errorf("the config file contains a property (%s) that is not configurable", propertyKey))
+			errs = append(errs, propertyErrorf(propertyKey, "the config file contains a property (%s) that is not configurable", propertyKey))
 		}
 
 		if property.Type == "secret" && hasValue {
@@ -79,9 +106,9 @@ func checkTileProperties(checkForRequiredProperties bool, propertyPrefix string,
 			var ok bool
 			var secret string
 			if value, ok = configValues[propertyKey].Value.(map[string]interface{}); !ok {
-				errs = append(errs, fmt.Errorf("the config file value for property (%s) is not in the right format. Should be {\"secret\": \"<SECRET VALUE>\"}", propertyKey))
+				errs = append(errs, propertyErrorf(propertyKey, "the config file value for property (%s) is not in the right format. Should be {\"secret\": \"<SECRET VALUE>\"}", propertyKey))
 			} else if secret, ok = value["secret"].(string); !ok {
-				errs = append(errs, fmt.Errorf("the config file value for property (%s) is not in the right format. Should be {\"secret\": \"<SECRET VALUE>\"}", propertyKey))
+				errs = append(errs, propertyErrorf(propertyKey, "the config file value for property (%s) is not in the right format. Should be {\"secret\": \"<SECRET VALUE>\"}", propertyKey))
 			} else if secret == "" {
 				hasValue = false
 			}
@@ -91,7 +118,7 @@ func checkTileProperties(checkForRequiredProperties bool, propertyPrefix string,
 			if property.Configurable && !property.Optional {
 				if property.Default == nil && property.Type != "dropdown_select" {
 					if !hasValue {
-						errs = append(errs, fmt.Errorf("the config file is missing a required property (%s)", propertyKey))
+						errs = append(errs, missingPropertyErrorf(propertyKey, "the config file is missing a required property (%s)", propertyKey))
 					}
 				}
 			}
@@ -109,7 +136,7 @@ func checkTileProperties(checkForRequiredProperties bool, propertyPrefix string,
 				if !isSelected {
 					for _, childKey := range childKeys {
 						if configValues[childKey] != nil {
-							errs = append(errs, fmt.Errorf("the config file contains a property (%s) that is not selected", childKey))
+							errs = append(errs, propertyErrorf(childKey, "the config file contains a property (%s) that is not selected", childKey))
 						}
 					}
 				}
@@ -124,7 +151,7 @@ func checkTileProperties(checkForRequiredProperties bool, propertyPrefix string,
 				}
 			}
 			if !validValue {
-				errs = append(errs, fmt.Errorf("the config file value for property (%s) is invalid: %v", propertyKey, configValues[propertyKey].Value))
+				errs = append(errs, propertyErrorf(propertyKey, "the config file value for property (%s) is invalid: %v", propertyKey, configValues[propertyKey].Value))
 			}
 		}
 
@@ -136,7 +163,7 @@ func checkTileProperties(checkForRequiredProperties bool, propertyPrefix string,
 				validKeys = append(validKeys, childKeys...)
 				errs = append(errs, childErrs...)
 			} else {
-				errs = append(errs, fmt.Errorf("the config file value for the collection blueprints (%s) is not in the right format. Should be [ { \"name\": \"value\", ... }, ... ]", propertyKey))
+				errs = append(errs, propertyErrorf(propertyKey, "the config file value for the collection blueprints (%s) is not in the right format. Should be [ { \"name\": \"value\", ... }, ... ]", propertyKey))
 			}
 		}
 	}
@@ -156,23 +183,32 @@ func (cmd *Config) CompareProperties(configFile *tileinspect.ConfigFile, tilePro
 
 	for key := range configFile.ProductProperties {
 		if !stringInSlice(key, validKeys) {
-			errs = append(errs, fmt.Errorf("the config file contains a property (%s) that is not defined in the tile", key))
+			errs = append(errs, propertyErrorf(key, "the config file contains a property (%s) that is not defined in the tile", key))
 		}
 	}
 
 	return errs
 }
 
-func (cmd *Config) CheckConfig(out io.Writer) error {
-	configFileContents, err := os.ReadFile(cmd.ConfigFilePath)
+func LoadConfigFile(path string) (*tileinspect.ConfigFile, error) {
+	configFileContents, err := os.ReadFile(path)
 	if err != nil {
-		return Wrapf(err, "failed to read the config file: %s", cmd.ConfigFilePath)
+		return nil, Wrapf(err, "failed to read the config file: %s", path)
 	}
 
 	configFile := &tileinspect.ConfigFile{}
 	err = yaml.Unmarshal(configFileContents, configFile)
 	if err != nil {
-		return Wrap(err, "the config file does not contain valid JSON or YAML")
+		return nil, Wrap(err, "the config file does not contain valid JSON or YAML")
+	}
+
+	return configFile, nil
+}
+
+func (cmd *Config) CheckConfig(out io.Writer) error {
+	configFile, err := LoadConfigFile(cmd.ConfigFilePath)
+	if err != nil {
+		return err
 	}
 
 	if configFile.ProductProperties == nil {
@@ -187,17 +223,22 @@ func (cmd *Config) CheckConfig(out io.Writer) error {
 
 	errs := cmd.CompareProperties(configFile, tileProperties)
 	if len(errs) > 0 {
-		errorStrings := make([]string, len(errs))
-		for i := range errs {
-			errorStrings[i] = errs[i].Error()
-		}
-		return errors.New(strings.Join(errorStrings, "\n"))
+		return errors.New(JoinErrors(errs))
 	}
 
 	_, _ = out.Write([]byte("The config file appears to be valid\n"))
 	return nil
 }
 
+// JoinErrors puts each error of CompareProperties on its own line
+func JoinErrors(errs []error) string {
+	errorStrings := make([]string, len(errs))
+	for i := range errs {
+		errorStrings[i] = errs[i].Error()
+	}
+	return strings.Join(errorStrings, "\n")
+}
+
 func (cmd *Config) Execute(args []string) error {
 	cmd.MetadataCmd = &metadata.Config{
 		TileConfig: tileinspect.TileConfig{
@@ -11,6 +11,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gbytes"
+	"github.com/pkg/errors"
 )
 
 func makeConfigFile(contents string) (*os.File, error) {
@@ -487,6 +488,9 @@ var _ = Describe("CompareProperties", func() {
 				errs := checkConfig.CompareProperties(configFile, tileProperties)
 				Expect(errs).To(HaveLen(1))
 				Expect(errs[0].Error()).To(ContainSubstring("the config file is missing a required property (.properties.simple-property)"))
+				Expect(errs[0]).To(BeAssignableToTypeOf(&checkconfig.PropertyError{}))
+				Expect(errs[0].(*checkconfig.PropertyError).Key).To(Equal(".properties.simple-property"))
+				Expect(errs[0].(*checkconfig.PropertyError).Missing).To(BeTrue())
 			})
 		})
 	})
@@ -671,6 +675,9 @@ var _ = Describe("CompareProperties", func() {
 				errs := checkConfig.CompareProperties(configFile, tileProperties)
 				Expect(errs).To(HaveLen(1))
 				Expect(errs[0].Error()).To(Equal("the config file value for property (.properties.flow-rate) is invalid: ludicrous"))
+				Expect(errs[0]).To(BeAssignableToTypeOf(&checkconfig.PropertyError{}))
+				Expect(errs[0].(*checkconfig.PropertyError).Key).To(Equal(".properties.flow-rate"))
+				Expect(errs[0].(*checkconfig.PropertyError).Missing).To(BeFalse())
 			})
 		})
 
@@ -1251,3 +1258,9 @@ var _ = Describe("CompareProperties", func() {
 		})
 	})
 })
+
+var _ = Describe("JoinErrors", func() {
+	It("puts each error on its own line", func() {
+		Expect(checkconfig.JoinErrors([]error{errors.New("first"), errors.New("second")})).To(Equal("first\nsecond"))
+	})
+})
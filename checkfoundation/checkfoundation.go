@@ -0,0 +1,295 @@
+package checkfoundation
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/checkconfig"
+	"github.com/cf-platform-eng/tileinspect/metadata"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	Dir      string `long:"dir" short:"d" description:"path to a directory with the tiles (*.pivotal) and config files (*.yml, *.yaml, *.json) of a foundation" required:"true"`
+	Parallel int    `long:"parallel" description:"number of tiles to load at the same time" default:"4"`
+	// MetadataCmdFor returns the command used to load the metadata of a tile
+	MetadataCmdFor func(tile string) tileinspect.MetadataCmd
+}
+
+// Result is the outcome of checking the config file of a product against its tile
+type Result struct {
+	ProductName string
+	ConfigFile  string
+	Tile        string
+	Errors      []error
+}
+
+type Report struct {
+	Results []*Result
+	// MissingTiles are the config files without a tile for their product
+	MissingTiles []*Result
+	// MissingConfigs are the tiles without a config file for their product
+	MissingConfigs []*Result
+}
+
+type loadedTile struct {
+	path       string
+	properties *tileinspect.TileProperties
+	err        error
+}
+
+type loadedConfig struct {
+	path   string
+	config *tileinspect.ConfigFile
+	err    error
+}
+
+func (cmd *Config) findFiles() ([]string, []string, error) {
+	entries, err := os.ReadDir(cmd.Dir)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read the foundation directory: %s", cmd.Dir)
+	}
+
+	var tiles, configFiles []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(cmd.Dir, entry.Name())
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".pivotal":
+			tiles = append(tiles, path)
+		case ".yml", ".yaml", ".json":
+			configFiles = append(configFiles, path)
+		}
+	}
+	return tiles, configFiles, nil
+}
+
+func (cmd *Config) loadTiles(paths []string) []*loadedTile {
+	parallel := cmd.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	tiles := make([]*loadedTile, len(paths))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < parallel; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				tileProperties := &tileinspect.TileProperties{}
+				err := cmd.MetadataCmdFor(paths[i]).LoadMetadata(tileProperties)
+				if err != nil {
+					err = errors.Wrap(err, "failed to load metadata from the tile")
+				}
+				tiles[i] = &loadedTile{path: paths[i], properties: tileProperties, err: err}
+			}
+		}()
+	}
+	for i := range paths {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return tiles
+}
+
+// isConfigFile is false for YAML and JSON files that are not an om config, e.g. a vars file or the index of make-config --matrix.
+// Files that cannot be parsed are assumed to be broken config files.
+func isConfigFile(path string) bool {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return true
+	}
+
+	var document interface{}
+	err = yaml.Unmarshal(contents, &document)
+	if err != nil {
+		return true
+	}
+
+	fields, ok := document.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	productName, _ := fields["product-name"].(string)
+	return productName != ""
+}
+
+func loadConfigFiles(paths []string) []*loadedConfig {
+	var configFiles []*loadedConfig
+	for _, path := range paths {
+		if !isConfigFile(path) {
+			continue
+		}
+		config, err := checkconfig.LoadConfigFile(path)
+		configFiles = append(configFiles, &loadedConfig{path: path, config: config, err: err})
+	}
+	return configFiles
+}
+
+func checkPair(result *Result, tile *loadedTile, config *loadedConfig) {
+	if config.config.ProductProperties == nil {
+		result.Errors = append(result.Errors, errors.New(`the config file is missing a "product-properties" section`))
+		return
+	}
+
+	check := &checkconfig.Config{}
+	result.Errors = check.CompareProperties(config.config, tile.properties)
+}
+
+func (cmd *Config) CheckFoundation() (*Report, error) {
+	tilePaths, configPaths, err := cmd.findFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	tilesByName := make(map[string]*loadedTile)
+	for _, tile := range cmd.loadTiles(tilePaths) {
+		name := tile.properties.Name
+		if tile.err != nil {
+			report.Results = append(report.Results, &Result{Tile: tile.path, Errors: []error{tile.err}})
+			continue
+		}
+		if other, ok := tilesByName[name]; ok {
+			report.Results = append(report.Results, &Result{
+				ProductName: name,
+				Tile:        tile.path,
+				Errors:      []error{errors.Errorf("another tile for this product was found (%s)", other.path)},
+			})
+			continue
+		}
+		tilesByName[name] = tile
+	}
+
+	var wg sync.WaitGroup
+	pairedTiles := make(map[string]bool)
+	for _, config := range loadConfigFiles(configPaths) {
+		if config.err != nil {
+			report.Results = append(report.Results, &Result{ConfigFile: config.path, Errors: []error{config.err}})
+			continue
+		}
+
+		name := config.config.ProductName
+		tile, ok := tilesByName[name]
+		if !ok {
+			report.MissingTiles = append(report.MissingTiles, &Result{ProductName: name, ConfigFile: config.path})
+			continue
+		}
+		if pairedTiles[name] {
+			report.Results = append(report.Results, &Result{
+				ProductName: name,
+				ConfigFile:  config.path,
+				Errors:      []error{errors.New("another config file for this product was found")},
+			})
+			continue
+		}
+		pairedTiles[name] = true
+
+		result := &Result{ProductName: name, ConfigFile: config.path, Tile: tile.path}
+		report.Results = append(report.Results, result)
+		wg.Add(1)
+		go func(tile *loadedTile, config *loadedConfig) {
+			defer wg.Done()
+			checkPair(result, tile, config)
+		}(tile, config)
+	}
+	wg.Wait()
+
+	for name, tile := range tilesByName {
+		if !pairedTiles[name] {
+			report.MissingConfigs = append(report.MissingConfigs, &Result{ProductName: name, Tile: tile.path})
+		}
+	}
+
+	for _, results := range [][]*Result{report.Results, report.MissingTiles, report.MissingConfigs} {
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].ProductName < results[j].ProductName
+		})
+	}
+	return report, nil
+}
+
+// Problems is the number of products that failed their check or are missing a tile or config file
+func (report *Report) Problems() int {
+	problems := len(report.MissingTiles) + len(report.MissingConfigs)
+	for _, result := range report.Results {
+		if len(result.Errors) > 0 {
+			problems++
+		}
+	}
+	return problems
+}
+
+func describe(result *Result) string {
+	var files []string
+	for _, file := range []string{result.ConfigFile, result.Tile} {
+		if file != "" {
+			files = append(files, filepath.Base(file))
+		}
+	}
+	if result.ProductName == "" {
+		return strings.Join(files, ", ")
+	}
+	return fmt.Sprintf("%s (%s)", result.ProductName, strings.Join(files, ", "))
+}
+
+func (report *Report) Write(out io.Writer) {
+	passed := 0
+	for _, result := range report.Results {
+		if len(result.Errors) == 0 {
+			passed++
+			_, _ = fmt.Fprintf(out, "PASS %s\n", describe(result))
+			continue
+		}
+
+		_, _ = fmt.Fprintf(out, "FAIL %s\n", describe(result))
+		for _, err := range result.Errors {
+			_, _ = fmt.Fprintf(out, "  %s\n", err.Error())
+		}
+	}
+	for _, result := range report.MissingTiles {
+		_, _ = fmt.Fprintf(out, "MISSING TILE %s\n", describe(result))
+	}
+	for _, result := range report.MissingConfigs {
+		_, _ = fmt.Fprintf(out, "MISSING CONFIG %s\n", describe(result))
+	}
+
+	_, _ = fmt.Fprintf(out, "\n%d passed, %d failed, %d missing a tile, %d missing a config file\n",
+		passed, len(report.Results)-passed, len(report.MissingTiles), len(report.MissingConfigs))
+}
+
+func (cmd *Config) Execute(args []string) error {
+	if cmd.MetadataCmdFor == nil {
+		cmd.MetadataCmdFor = func(tile string) tileinspect.MetadataCmd {
+			return &metadata.Config{
+				TileConfig: tileinspect.TileConfig{
+					Tile: tile,
+				},
+			}
+		}
+	}
+
+	report, err := cmd.CheckFoundation()
+	if err != nil {
+		return err
+	}
+
+	report.Write(os.Stdout)
+	if problems := report.Problems(); problems > 0 {
+		return errors.Errorf("the foundation has %d problems", problems)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package checkfoundation_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCheckFoundation(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CheckFoundation Suite")
+}
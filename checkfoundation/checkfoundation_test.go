@@ -0,0 +1,219 @@
+package checkfoundation_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/checkfoundation"
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("CheckFoundation", func() {
+	var (
+		cmd          *checkfoundation.Config
+		dir          string
+		metadataCmds map[string]*tileinspectfakes.FakeMetadataCmd
+	)
+
+	writeFile := func(name string, contents string) {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	addTile := func(fileName string, productName string) *tileinspectfakes.FakeMetadataCmd {
+		writeFile(fileName, "")
+		metadataCmd := &tileinspectfakes.FakeMetadataCmd{}
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := yaml.Unmarshal([]byte(fmt.Sprintf(heredoc.Doc(`
+			---
+			name: %s
+			property_blueprints:
+			  - name: hostname
+			    type: string
+			    configurable: true
+			`), productName)), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+		metadataCmds[filepath.Join(dir, fileName)] = metadataCmd
+		return metadataCmd
+	}
+
+	addConfig := func(fileName string, productName string, valid bool) {
+		properties := "\n  .properties.hostname:\n    value: example.com\n"
+		if !valid {
+			properties = " {}\n"
+		}
+		writeFile(fileName, fmt.Sprintf("product-name: %s\nproduct-properties:%s", productName, properties))
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		metadataCmds = make(map[string]*tileinspectfakes.FakeMetadataCmd)
+		cmd = &checkfoundation.Config{
+			Dir: dir,
+			MetadataCmdFor: func(tile string) tileinspect.MetadataCmd {
+				return metadataCmds[tile]
+			},
+		}
+
+		addTile("a-1.0.pivotal", "product-a")
+		addTile("b-1.0.pivotal", "product-b")
+		addConfig("a.yml", "product-a", true)
+		addConfig("b.json", "product-b", false)
+		writeFile("vars.yml", "password: secret\n")
+		writeFile("README.md", "# Foundation\n")
+	})
+
+	It("checks each config file against the tile of its product", func() {
+		report, err := cmd.CheckFoundation()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.Results).To(HaveLen(2))
+		Expect(report.Results[0].ProductName).To(Equal("product-a"))
+		Expect(report.Results[0].ConfigFile).To(Equal(filepath.Join(dir, "a.yml")))
+		Expect(report.Results[0].Tile).To(Equal(filepath.Join(dir, "a-1.0.pivotal")))
+		Expect(report.Results[0].Errors).To(BeEmpty())
+
+		Expect(report.Results[1].ProductName).To(Equal("product-b"))
+		Expect(report.Results[1].Errors).To(HaveLen(1))
+		Expect(report.Problems()).To(Equal(1))
+	})
+
+	It("writes a summary", func() {
+		report, err := cmd.CheckFoundation()
+		Expect(err).ToNot(HaveOccurred())
+
+		buffer := NewBuffer()
+		defer buffer.Close()
+		report.Write(buffer)
+		Expect(string(buffer.Contents())).To(Equal(heredoc.Doc(`
+			PASS product-a (a.yml, a-1.0.pivotal)
+			FAIL product-b (b.json, b-1.0.pivotal)
+			  the config file is missing a required property (.properties.hostname)
+
+			1 passed, 1 failed, 0 missing a tile, 0 missing a config file
+			`)))
+	})
+
+	Context("products are missing a tile or a config file", func() {
+		BeforeEach(func() {
+			addTile("c-1.0.pivotal", "product-c")
+			addConfig("d.yml", "product-d", true)
+		})
+
+		It("reports them", func() {
+			report, err := cmd.CheckFoundation()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(report.MissingConfigs).To(HaveLen(1))
+			Expect(report.MissingConfigs[0].ProductName).To(Equal("product-c"))
+			Expect(report.MissingTiles).To(HaveLen(1))
+			Expect(report.MissingTiles[0].ProductName).To(Equal("product-d"))
+			Expect(report.Problems()).To(Equal(3))
+
+			buffer := NewBuffer()
+			defer buffer.Close()
+			report.Write(buffer)
+			Expect(string(buffer.Contents())).To(ContainSubstring("MISSING TILE product-d (d.yml)\n"))
+			Expect(string(buffer.Contents())).To(ContainSubstring("MISSING CONFIG product-c (c-1.0.pivotal)\n"))
+			Expect(string(buffer.Contents())).To(ContainSubstring("1 passed, 1 failed, 1 missing a tile, 1 missing a config file\n"))
+		})
+	})
+
+	Context("two config files are for the same product", func() {
+		BeforeEach(func() {
+			addConfig("a-copy.yml", "product-a", true)
+		})
+
+		It("reports the second one", func() {
+			report, err := cmd.CheckFoundation()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Problems()).To(Equal(2))
+			Expect(report.Results).To(ContainElement(HaveField("Errors", ConsistOf(MatchError("another config file for this product was found")))))
+		})
+	})
+
+	Context("a config file is not valid YAML", func() {
+		BeforeEach(func() {
+			writeFile("broken.yml", "product-name: [")
+		})
+
+		It("reports it", func() {
+			report, err := cmd.CheckFoundation()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Problems()).To(Equal(2))
+			Expect(report.Results[0].ConfigFile).To(Equal(filepath.Join(dir, "broken.yml")))
+			Expect(report.Results[0].Errors[0].Error()).To(HavePrefix("the config file does not contain valid JSON or YAML"))
+		})
+	})
+
+	Context("the directory has the index of a config matrix", func() {
+		BeforeEach(func() {
+			writeFile("index.yaml", "- file: a.yml\n  selections: []\n")
+		})
+
+		It("ignores it", func() {
+			report, err := cmd.CheckFoundation()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Problems()).To(Equal(1))
+			Expect(report.Results).To(HaveLen(2))
+		})
+	})
+
+	Context("many tiles are loaded at the same time", func() {
+		BeforeEach(func() {
+			for i := 0; i < 10; i++ {
+				addTile(fmt.Sprintf("c-%d.pivotal", i), fmt.Sprintf("product-c%d", i))
+				addConfig(fmt.Sprintf("c%d.yml", i), fmt.Sprintf("product-c%d", i), true)
+			}
+			cmd.Parallel = 3
+		})
+
+		It("checks every product", func() {
+			report, err := cmd.CheckFoundation()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Problems()).To(Equal(1))
+			Expect(report.Results).To(HaveLen(12))
+			for _, metadataCmd := range metadataCmds {
+				Expect(metadataCmd.LoadMetadataCallCount()).To(Equal(1))
+			}
+		})
+	})
+
+	Context("a tile fails to load", func() {
+		BeforeEach(func() {
+			metadataCmd := addTile("broken.pivotal", "")
+			metadataCmd.LoadMetadataStub = nil
+			metadataCmd.LoadMetadataReturns(errors.New("metadata-error"))
+		})
+
+		It("reports it", func() {
+			report, err := cmd.CheckFoundation()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Problems()).To(Equal(2))
+			Expect(report.Results[0].Tile).To(Equal(filepath.Join(dir, "broken.pivotal")))
+			Expect(report.Results[0].Errors[0].Error()).To(Equal("failed to load metadata from the tile: metadata-error"))
+		})
+	})
+
+	Context("the directory does not exist", func() {
+		BeforeEach(func() {
+			cmd.Dir = "/does/not/exist"
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.CheckFoundation()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("failed to read the foundation directory: /does/not/exist"))
+		})
+	})
+})
@@ -5,10 +5,19 @@ import (
 	"os"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect/apipayload"
 	"github.com/cf-platform-eng/tileinspect/checkconfig"
+	"github.com/cf-platform-eng/tileinspect/checkfoundation"
+	"github.com/cf-platform-eng/tileinspect/dependencies"
+	"github.com/cf-platform-eng/tileinspect/extract"
+	"github.com/cf-platform-eng/tileinspect/importconfig"
+	"github.com/cf-platform-eng/tileinspect/ls"
 	"github.com/cf-platform-eng/tileinspect/makeconfig"
+	"github.com/cf-platform-eng/tileinspect/releases"
+	"github.com/cf-platform-eng/tileinspect/schema"
 
 	"github.com/cf-platform-eng/tileinspect/stemcell"
+	"github.com/cf-platform-eng/tileinspect/verify"
 	"github.com/jessevdk/go-flags"
 
 	"github.com/cf-platform-eng/tileinspect"
@@ -17,9 +26,18 @@ import (
 )
 
 var checkConfigOpts checkconfig.Config
+var checkDependenciesOpts dependencies.Config
+var checkFoundationOpts checkfoundation.Config
+var extractOpts extract.Config
+var importConfigOpts importconfig.Config
+var lsOpts ls.Config
 var makeConfigOpts makeconfig.Config
 var metadataOpts metadata.Config
+var releasesOpts releases.Config
+var schemaOpts schema.Config
 var stemcellOpts stemcell.Config
+var toAPIPayloadOpts apipayload.Config
+var verifyOpts verify.Config
 var config tileinspect.Config
 var parser = flags.NewParser(&config, flags.Default)
 
@@ -35,6 +53,68 @@ func main() {
 		os.Exit(1)
 	}
 
+	_, err = parser.AddCommand(
+		"check-dependencies",
+		"Check the product dependencies of tiles",
+		heredoc.Doc(`Check that the requires_product_versions of each tile are met by the other tiles,
+		their provides_product_versions, or a list of deployed products.
+		Products that are provided at different versions are reported as conflicts.`),
+		&checkDependenciesOpts,
+	)
+	if err != nil {
+		fmt.Println("Could not add check-dependencies command")
+		os.Exit(1)
+	}
+
+	_, err = parser.AddCommand(
+		"check-foundation",
+		"Check the config files of a foundation",
+		heredoc.Doc(`Check every config file in a directory against the tile for its product.
+		Config files are paired with tiles by their product-name and the name in the tile metadata.
+		Products without a tile or without a config file are reported as problems.`),
+		&checkFoundationOpts,
+	)
+	if err != nil {
+		fmt.Println("Could not add check-foundation command")
+		os.Exit(1)
+	}
+
+	_, err = parser.AddCommand(
+		"extract",
+		"Extract files from the tile",
+		"Extract the files matching the globs from the tile into a directory, or write a single file to stdout",
+		&extractOpts,
+	)
+	if err != nil {
+		fmt.Println("Could not add extract command")
+		os.Exit(1)
+	}
+
+	_, err = parser.AddCommand(
+		"import-config",
+		"Import a config file from Ops Manager",
+		heredoc.Doc(`Make a config file from the staged properties of a product that is already configured in Ops Manager.
+		The staged properties are the JSON returned by GET /api/v0/staged/products/:guid/properties.
+		Unconfigurable properties and properties with default values are left out.
+		Ops Manager does not return secrets, so they are replaced with ((variable)) placeholders.`),
+		&importConfigOpts,
+	)
+	if err != nil {
+		fmt.Println("Could not add import-config command")
+		os.Exit(1)
+	}
+
+	_, err = parser.AddCommand(
+		"ls",
+		"List the files in the tile",
+		"List the files in the tile with their size and compressed size, by top-level directory",
+		&lsOpts,
+	)
+	if err != nil {
+		fmt.Println("Could not add ls command")
+		os.Exit(1)
+	}
+
 	_, err = parser.AddCommand(
 		"make-config",
 		"Make a template config file",
@@ -42,10 +122,14 @@ func main() {
 		The config file will contain a value for each selected, configurable property.
 		The value will be, in order:
 		* a value defined using the "--value" parameter
+		* a value defined in the "--values-file" file
 		* a default value defined in the tile
 		* for dropdown_select and selector properties, the first option
 		* a sample value that is meant to be replaced by the user
 		
+		The config file also contains network-properties, resource-config and errand-config sections,
+		populated with placeholders and the defaults defined in the tile.
+		
 		Using the -v, --value parameter is useful for setting known values or for selecting a preferred option in a selector.
 		
 		Example: tileinspect make-config -t my-tile.pivotal -v .properties.network_selector:"Use TCP"`),
@@ -67,6 +151,28 @@ func main() {
 		os.Exit(1)
 	}
 
+	_, err = parser.AddCommand(
+		"releases",
+		"List BOSH releases",
+		"List the BOSH releases in the tile, with the jobs and packages from their release.MF",
+		&releasesOpts,
+	)
+	if err != nil {
+		fmt.Println("Could not add releases command")
+		os.Exit(1)
+	}
+
+	_, err = parser.AddCommand(
+		"schema",
+		"Dump config file schema",
+		"Dump a JSON Schema for the config file of this tile to stdout",
+		&schemaOpts,
+	)
+	if err != nil {
+		fmt.Println("Could not add schema command")
+		os.Exit(1)
+	}
+
 	_, err = parser.AddCommand(
 		"stemcell",
 		"Dump stemcell requirement",
@@ -78,6 +184,28 @@ func main() {
 		os.Exit(1)
 	}
 
+	_, err = parser.AddCommand(
+		"to-api-payload",
+		"Convert a config file into an Ops Manager API request",
+		"Convert the product-properties of a config file into the request body for PUT /api/v0/staged/products/:guid/properties",
+		&toAPIPayloadOpts,
+	)
+	if err != nil {
+		fmt.Println("Could not add to-api-payload command")
+		os.Exit(1)
+	}
+
+	_, err = parser.AddCommand(
+		"verify",
+		"Verify the integrity of the tile",
+		"Check the zip file and the CRC-32 checksum of every file in the tile, and compare the SHA-256 checksum of the tile against --sha256 or <tile>.sha256",
+		&verifyOpts,
+	)
+	if err != nil {
+		fmt.Println("Could not add verify command")
+		os.Exit(1)
+	}
+
 	_, err = parser.AddCommand(
 		"version",
 		"print version",
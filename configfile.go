@@ -3,6 +3,9 @@ package tileinspect
 type ConfigFile struct {
 	ProductName       string                         `json:"product-name"`
 	ProductProperties map[string]*ConfigFileProperty `json:"product-properties"`
+	NetworkProperties *NetworkProperties             `json:"network-properties,omitempty"`
+	ResourceConfig    map[string]*ResourceConfig     `json:"resource-config,omitempty"`
+	ErrandConfig      map[string]*ErrandConfig       `json:"errand-config,omitempty"`
 }
 
 type ConfigFileProperty struct {
@@ -10,3 +13,33 @@ type ConfigFileProperty struct {
 	Value    interface{} `json:"value"`
 	Required *bool       `json:"required,omitempty"`
 }
+
+type NamedReference struct {
+	Name string `json:"name"`
+}
+
+type NetworkProperties struct {
+	Network                   NamedReference   `json:"network"`
+	ServiceNetwork            *NamedReference  `json:"service_network,omitempty"`
+	OtherAvailabilityZones    []NamedReference `json:"other_availability_zones"`
+	SingletonAvailabilityZone NamedReference   `json:"singleton_availability_zone"`
+}
+
+type ResourceConfig struct {
+	Instances      interface{}         `json:"instances"`
+	PersistentDisk *PersistentDiskSize `json:"persistent_disk,omitempty"`
+	InstanceType   InstanceType        `json:"instance_type"`
+}
+
+type PersistentDiskSize struct {
+	SizeMB string `json:"size_mb"`
+}
+
+type InstanceType struct {
+	ID string `json:"id"`
+}
+
+type ErrandConfig struct {
+	PostDeployState interface{} `json:"post-deploy-state,omitempty"`
+	PreDeleteState  interface{} `json:"pre-delete-state,omitempty"`
+}
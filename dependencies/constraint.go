@@ -0,0 +1,163 @@
+package dependencies
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Version is a dotted version, e.g. 2.11.3 or 2.11.0-build.4
+type Version struct {
+	Segments   []int
+	PreRelease string
+	original   string
+}
+
+func ParseVersion(version string) (*Version, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	parsed := &Version{original: version}
+
+	release := version
+	if index := strings.IndexAny(version, "-+"); index >= 0 {
+		release = version[:index]
+		if version[index] == '-' {
+			parsed.PreRelease = strings.SplitN(version[index+1:], "+", 2)[0]
+		}
+	}
+
+	for _, segment := range strings.Split(release, ".") {
+		number, err := strconv.Atoi(segment)
+		if err != nil || number < 0 {
+			return nil, errors.Errorf("invalid version: %q", version)
+		}
+		parsed.Segments = append(parsed.Segments, number)
+	}
+	return parsed, nil
+}
+
+func (v *Version) String() string {
+	return v.original
+}
+
+func (v *Version) segment(i int) int {
+	if i < len(v.Segments) {
+		return v.Segments[i]
+	}
+	return 0
+}
+
+// Compare returns -1, 0 or 1 when v is lower than, equal to or higher than other.
+// Missing segments count as 0, and a pre-release is lower than its release.
+func (v *Version) Compare(other *Version) int {
+	length := len(v.Segments)
+	if len(other.Segments) > length {
+		length = len(other.Segments)
+	}
+
+	for i := 0; i < length; i++ {
+		if v.segment(i) != other.segment(i) {
+			if v.segment(i) < other.segment(i) {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case v.PreRelease == other.PreRelease:
+		return 0
+	case v.PreRelease == "":
+		return 1
+	case other.PreRelease == "":
+		return -1
+	case v.PreRelease < other.PreRelease:
+		return -1
+	}
+	return 1
+}
+
+type requirement struct {
+	operator string
+	version  *Version
+}
+
+// Constraint is a list of comma separated requirements that all need to be met, e.g. ">= 2.10, < 3" or "~> 2.11"
+type Constraint struct {
+	requirements []requirement
+	original     string
+}
+
+var operators = []string{"~>", ">=", "<=", "!=", ">", "<", "="}
+
+func ParseConstraint(constraint string) (*Constraint, error) {
+	parsed := &Constraint{original: strings.TrimSpace(constraint)}
+	for _, clause := range strings.Split(constraint, ",") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+
+		operator := "="
+		for _, candidate := range operators {
+			if strings.HasPrefix(clause, candidate) {
+				operator = candidate
+				clause = strings.TrimSpace(strings.TrimPrefix(clause, candidate))
+				break
+			}
+		}
+
+		version, err := ParseVersion(clause)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid version constraint: %q", constraint)
+		}
+		parsed.requirements = append(parsed.requirements, requirement{operator: operator, version: version})
+	}
+
+	if len(parsed.requirements) == 0 {
+		return nil, errors.Errorf("invalid version constraint: %q", constraint)
+	}
+	return parsed, nil
+}
+
+func (c *Constraint) String() string {
+	return c.original
+}
+
+// pessimisticUpperBound is the first version not allowed by ~>, e.g. 3 for ~> 2.11 and 2.12 for ~> 2.11.0
+func pessimisticUpperBound(version *Version) *Version {
+	segments := append([]int{}, version.Segments...)
+	if len(segments) > 1 {
+		segments = segments[:len(segments)-1]
+	}
+	segments[len(segments)-1]++
+	return &Version{Segments: segments}
+}
+
+func (r requirement) allows(version *Version) bool {
+	comparison := version.Compare(r.version)
+	switch r.operator {
+	case "~>":
+		return comparison >= 0 && version.Compare(pessimisticUpperBound(r.version)) < 0
+	case ">=":
+		return comparison >= 0
+	case "<=":
+		return comparison <= 0
+	case "!=":
+		return comparison != 0
+	case ">":
+		return comparison > 0
+	case "<":
+		return comparison < 0
+	}
+	return comparison == 0
+}
+
+func (c *Constraint) Allows(version *Version) bool {
+	for _, requirement := range c.requirements {
+		if !requirement.allows(version) {
+			return false
+		}
+	}
+	return true
+}
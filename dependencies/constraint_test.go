@@ -0,0 +1,77 @@
+package dependencies_test
+
+import (
+	"github.com/cf-platform-eng/tileinspect/dependencies"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Version", func() {
+	compare := func(a, b string) int {
+		versionA, err := dependencies.ParseVersion(a)
+		Expect(err).ToNot(HaveOccurred())
+		versionB, err := dependencies.ParseVersion(b)
+		Expect(err).ToNot(HaveOccurred())
+		return versionA.Compare(versionB)
+	}
+
+	It("compares versions", func() {
+		Expect(compare("2.11.3", "2.11.3")).To(Equal(0))
+		Expect(compare("2.11", "2.11.0")).To(Equal(0))
+		Expect(compare("2.9", "2.11")).To(Equal(-1))
+		Expect(compare("3.0.0", "2.11.3")).To(Equal(1))
+		Expect(compare("2.11.0-build.1", "2.11.0")).To(Equal(-1))
+		Expect(compare("2.11.0-build.2", "2.11.0-build.1")).To(Equal(1))
+		Expect(compare("v1.2.3", "1.2.3")).To(Equal(0))
+	})
+
+	It("rejects invalid versions", func() {
+		_, err := dependencies.ParseVersion("latest")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`invalid version: "latest"`))
+	})
+})
+
+var _ = Describe("Constraint", func() {
+	allows := func(constraint string, version string) bool {
+		parsedConstraint, err := dependencies.ParseConstraint(constraint)
+		Expect(err).ToNot(HaveOccurred())
+		parsedVersion, err := dependencies.ParseVersion(version)
+		Expect(err).ToNot(HaveOccurred())
+		return parsedConstraint.Allows(parsedVersion)
+	}
+
+	It("supports comparison operators", func() {
+		Expect(allows(">= 2.11", "2.11.0")).To(BeTrue())
+		Expect(allows(">= 2.11", "2.10.9")).To(BeFalse())
+		Expect(allows("> 2.11", "2.11.0")).To(BeFalse())
+		Expect(allows("< 3", "2.99")).To(BeTrue())
+		Expect(allows("<= 3", "3.0.1")).To(BeFalse())
+		Expect(allows("!= 2.11.1", "2.11.1")).To(BeFalse())
+		Expect(allows("= 2.11.1", "2.11.1")).To(BeTrue())
+		Expect(allows("2.11.1", "2.11.2")).To(BeFalse())
+	})
+
+	It("supports pessimistic constraints", func() {
+		Expect(allows("~> 2.11", "2.11.0")).To(BeTrue())
+		Expect(allows("~> 2.11", "2.99.0")).To(BeTrue())
+		Expect(allows("~> 2.11", "3.0.0")).To(BeFalse())
+		Expect(allows("~> 2.11", "2.10.0")).To(BeFalse())
+		Expect(allows("~> 2.11.0", "2.11.9")).To(BeTrue())
+		Expect(allows("~> 2.11.0", "2.12.0")).To(BeFalse())
+	})
+
+	It("requires all comma separated requirements", func() {
+		Expect(allows(">= 2.10, < 2.12", "2.11.5")).To(BeTrue())
+		Expect(allows(">= 2.10, < 2.12", "2.12.0")).To(BeFalse())
+	})
+
+	It("rejects invalid constraints", func() {
+		_, err := dependencies.ParseConstraint(">= latest")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`invalid version constraint: ">= latest": invalid version: "latest"`))
+
+		_, err = dependencies.ParseConstraint("")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,246 @@
+package dependencies
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/metadata"
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	Tiles    []string `long:"tile" short:"t" description:"path to a product file (can be repeated)" required:"true"`
+	Deployed string   `long:"deployed" description:"path to a JSON list of the deployed products, e.g. the output of GET /api/v0/deployed/products"`
+	// MetadataCmdFor returns the command used to load the metadata of a tile
+	MetadataCmdFor func(tile string) tileinspect.MetadataCmd
+}
+
+// DeployedProduct accepts both the name and version fields, and the type and product_version fields of the Ops Manager API
+type DeployedProduct struct {
+	Name           string                    `json:"name"`
+	Type           string                    `json:"type"`
+	Version        tileinspect.VersionString `json:"version"`
+	ProductVersion tileinspect.VersionString `json:"product_version"`
+}
+
+// Provider is a tile or deployed product that provides a version of a product
+type Provider struct {
+	Version string
+	Source  string
+}
+
+type Dependency struct {
+	Product    string
+	Source     string
+	Requires   string
+	Constraint string
+	// Found is the provider that was checked against the constraint, nil when the product was not found
+	Found   *Provider
+	Problem string
+}
+
+type Conflict struct {
+	Product   string
+	Providers []Provider
+}
+
+type Report struct {
+	Satisfied   []*Dependency
+	Unsatisfied []*Dependency
+	Conflicts   []*Conflict
+}
+
+type providers map[string][]Provider
+
+func (p providers) add(name string, version string, source string) {
+	if name == "" || version == "" {
+		return
+	}
+	for _, provider := range p[name] {
+		if provider.Version == version {
+			return
+		}
+	}
+	p[name] = append(p[name], Provider{Version: version, Source: source})
+}
+
+func LoadDeployedProducts(path string) ([]DeployedProduct, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the deployed products: %s", path)
+	}
+
+	var products []DeployedProduct
+	err = json.Unmarshal(contents, &products)
+	if err != nil {
+		return nil, errors.Wrap(err, "the deployed products are not a valid JSON list")
+	}
+
+	for i, product := range products {
+		if product.Name == "" {
+			products[i].Name = product.Type
+		}
+		if product.Version == "" {
+			products[i].Version = product.ProductVersion
+		}
+	}
+	return products, nil
+}
+
+func checkDependency(dependency *Dependency, candidates []Provider) {
+	constraint, err := ParseConstraint(dependency.Constraint)
+	if err != nil {
+		dependency.Problem = err.Error()
+		return
+	}
+
+	if len(candidates) == 0 {
+		dependency.Problem = "not found"
+		return
+	}
+
+	for _, candidate := range candidates {
+		candidate := candidate
+		dependency.Found = &candidate
+
+		version, err := ParseVersion(candidate.Version)
+		if err != nil {
+			dependency.Problem = err.Error()
+			return
+		}
+		if !constraint.Allows(version) {
+			dependency.Problem = "does not match"
+			return
+		}
+	}
+}
+
+func (cmd *Config) CheckDependencies() (*Report, error) {
+	type loadedTile struct {
+		path       string
+		properties *tileinspect.TileProperties
+	}
+
+	var tiles []loadedTile
+	provided := make(providers)
+	for _, tile := range cmd.Tiles {
+		tileProperties := &tileinspect.TileProperties{}
+		err := cmd.MetadataCmdFor(tile).LoadMetadata(tileProperties)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load metadata from the tile: %s", tile)
+		}
+		tiles = append(tiles, loadedTile{path: tile, properties: tileProperties})
+
+		source := filepath.Base(tile)
+		provided.add(tileProperties.Name, string(tileProperties.ProductVersion), source)
+		for _, product := range tileProperties.ProvidesProductVersions {
+			provided.add(product.Name, string(product.Version), source)
+		}
+	}
+
+	// Tiles replace the deployed version of their product, so deployed products only count when no tile provides them
+	deployed := make(providers)
+	if cmd.Deployed != "" {
+		products, err := LoadDeployedProducts(cmd.Deployed)
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range products {
+			if _, ok := provided[product.Name]; !ok {
+				deployed.add(product.Name, string(product.Version), filepath.Base(cmd.Deployed))
+			}
+		}
+	}
+	for name, candidates := range deployed {
+		provided[name] = candidates
+	}
+
+	report := &Report{}
+	for _, tile := range tiles {
+		for _, required := range tile.properties.RequiresProductVersions {
+			dependency := &Dependency{
+				Product:    tile.properties.Name,
+				Source:     filepath.Base(tile.path),
+				Requires:   required.Name,
+				Constraint: string(required.Version),
+			}
+			checkDependency(dependency, provided[required.Name])
+
+			if dependency.Problem == "" {
+				report.Satisfied = append(report.Satisfied, dependency)
+			} else {
+				report.Unsatisfied = append(report.Unsatisfied, dependency)
+			}
+		}
+	}
+
+	for name, candidates := range provided {
+		if len(candidates) > 1 {
+			report.Conflicts = append(report.Conflicts, &Conflict{Product: name, Providers: candidates})
+		}
+	}
+	sort.Slice(report.Conflicts, func(i, j int) bool {
+		return report.Conflicts[i].Product < report.Conflicts[j].Product
+	})
+
+	return report, nil
+}
+
+func (report *Report) Problems() int {
+	return len(report.Unsatisfied) + len(report.Conflicts)
+}
+
+func describeDependency(dependency *Dependency) string {
+	description := fmt.Sprintf("%s (%s) requires %s %s", dependency.Product, dependency.Source, dependency.Requires, dependency.Constraint)
+	if dependency.Found != nil {
+		description += fmt.Sprintf(", found %s in %s", dependency.Found.Version, dependency.Found.Source)
+	}
+	return description
+}
+
+func (report *Report) Write(out io.Writer) {
+	for _, dependency := range report.Satisfied {
+		_, _ = fmt.Fprintf(out, "OK %s\n", describeDependency(dependency))
+	}
+	for _, dependency := range report.Unsatisfied {
+		_, _ = fmt.Fprintf(out, "UNSATISFIED %s: %s\n", describeDependency(dependency), dependency.Problem)
+	}
+	for _, conflict := range report.Conflicts {
+		var versions []string
+		for _, provider := range conflict.Providers {
+			versions = append(versions, fmt.Sprintf("%s in %s", provider.Version, provider.Source))
+		}
+		_, _ = fmt.Fprintf(out, "CONFLICT %s is provided at different versions: %s\n", conflict.Product, strings.Join(versions, ", "))
+	}
+
+	_, _ = fmt.Fprintf(out, "\n%d satisfied, %d unsatisfied, %d conflicting\n", len(report.Satisfied), len(report.Unsatisfied), len(report.Conflicts))
+}
+
+func (cmd *Config) Execute(args []string) error {
+	if cmd.MetadataCmdFor == nil {
+		cmd.MetadataCmdFor = func(tile string) tileinspect.MetadataCmd {
+			return &metadata.Config{
+				TileConfig: tileinspect.TileConfig{
+					Tile: tile,
+				},
+			}
+		}
+	}
+
+	report, err := cmd.CheckDependencies()
+	if err != nil {
+		return err
+	}
+
+	report.Write(os.Stdout)
+	if problems := report.Problems(); problems > 0 {
+		return errors.Errorf("found %d dependency problems", problems)
+	}
+	return nil
+}
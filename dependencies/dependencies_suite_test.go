@@ -0,0 +1,13 @@
+package dependencies_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDependencies(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dependencies Suite")
+}
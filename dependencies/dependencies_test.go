@@ -0,0 +1,196 @@
+package dependencies_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/dependencies"
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("CheckDependencies", func() {
+	var (
+		cmd          *dependencies.Config
+		metadataCmds map[string]*tileinspectfakes.FakeMetadataCmd
+	)
+
+	addTile := func(tile string, metadata string) *tileinspectfakes.FakeMetadataCmd {
+		metadataCmd := &tileinspectfakes.FakeMetadataCmd{}
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := yaml.Unmarshal([]byte(metadata), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+		metadataCmds[tile] = metadataCmd
+		cmd.Tiles = append(cmd.Tiles, tile)
+		return metadataCmd
+	}
+
+	BeforeEach(func() {
+		metadataCmds = make(map[string]*tileinspectfakes.FakeMetadataCmd)
+		cmd = &dependencies.Config{
+			MetadataCmdFor: func(tile string) tileinspect.MetadataCmd {
+				return metadataCmds[tile]
+			},
+		}
+
+		addTile("/tiles/cf-2.11.3.pivotal", heredoc.Doc(`
+			---
+			name: cf
+			product_version: 2.11.3
+			provides_product_versions:
+			  - name: cf
+			    version: 2.11.3
+			  - name: routing
+			    version: 0.2
+			`))
+		addTile("/tiles/mysql-2.10.0.pivotal", heredoc.Doc(`
+			---
+			name: pivotal-mysql
+			product_version: 2.10.0
+			requires_product_versions:
+			  - name: cf
+			    version: "~> 2.11"
+			  - name: routing
+			    version: ">= 0.2"
+			`))
+	})
+
+	It("reports satisfied dependencies", func() {
+		report, err := cmd.CheckDependencies()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Problems()).To(Equal(0))
+		Expect(report.Satisfied).To(HaveLen(2))
+		Expect(report.Satisfied[0].Requires).To(Equal("cf"))
+		Expect(report.Satisfied[0].Found).To(Equal(&dependencies.Provider{Version: "2.11.3", Source: "cf-2.11.3.pivotal"}))
+		Expect(report.Satisfied[1].Found.Version).To(Equal("0.2"))
+	})
+
+	Context("a dependency is not met", func() {
+		BeforeEach(func() {
+			addTile("/tiles/redis-3.0.0.pivotal", heredoc.Doc(`
+				---
+				name: p-redis
+				product_version: 3.0.0
+				requires_product_versions:
+				  - name: cf
+				    version: ">= 3.0"
+				  - name: p-bosh
+				    version: "~> 2.10"
+				`))
+		})
+
+		It("reports it", func() {
+			report, err := cmd.CheckDependencies()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Problems()).To(Equal(2))
+
+			buffer := NewBuffer()
+			defer buffer.Close()
+			report.Write(buffer)
+			Expect(string(buffer.Contents())).To(Equal(heredoc.Doc(`
+				OK pivotal-mysql (mysql-2.10.0.pivotal) requires cf ~> 2.11, found 2.11.3 in cf-2.11.3.pivotal
+				OK pivotal-mysql (mysql-2.10.0.pivotal) requires routing >= 0.2, found 0.2 in cf-2.11.3.pivotal
+				UNSATISFIED p-redis (redis-3.0.0.pivotal) requires cf >= 3.0, found 2.11.3 in cf-2.11.3.pivotal: does not match
+				UNSATISFIED p-redis (redis-3.0.0.pivotal) requires p-bosh ~> 2.10: not found
+
+				2 satisfied, 2 unsatisfied, 0 conflicting
+				`)))
+		})
+	})
+
+	Context("two tiles provide different versions of a product", func() {
+		BeforeEach(func() {
+			addTile("/tiles/cf-2.12.0.pivotal", "name: cf\nproduct_version: 2.12.0\n")
+		})
+
+		It("reports a conflict", func() {
+			report, err := cmd.CheckDependencies()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Conflicts).To(HaveLen(1))
+			Expect(report.Conflicts[0].Product).To(Equal("cf"))
+			Expect(report.Conflicts[0].Providers).To(ConsistOf(
+				dependencies.Provider{Version: "2.11.3", Source: "cf-2.11.3.pivotal"},
+				dependencies.Provider{Version: "2.12.0", Source: "cf-2.12.0.pivotal"},
+			))
+		})
+	})
+
+	Context("with a list of deployed products", func() {
+		var deployedPath string
+
+		BeforeEach(func() {
+			deployedPath = filepath.Join(GinkgoT().TempDir(), "deployed.json")
+			cmd.Deployed = deployedPath
+			err := os.WriteFile(deployedPath, []byte(`[
+				{"type": "p-bosh", "product_version": "2.10.4-build.12"},
+				{"name": "cf", "version": "2.10.0"}
+			]`), 0644)
+			Expect(err).ToNot(HaveOccurred())
+
+			addTile("/tiles/redis-3.0.0.pivotal", heredoc.Doc(`
+				---
+				name: p-redis
+				requires_product_versions:
+				  - name: p-bosh
+				    version: "~> 2.10"
+				`))
+		})
+
+		It("uses the deployed products that are not replaced by a tile", func() {
+			report, err := cmd.CheckDependencies()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Problems()).To(Equal(0))
+			Expect(report.Satisfied).To(HaveLen(3))
+			Expect(report.Satisfied[0].Found.Version).To(Equal("2.11.3"))
+			Expect(report.Satisfied[2].Found).To(Equal(&dependencies.Provider{Version: "2.10.4-build.12", Source: "deployed.json"}))
+		})
+
+		Context("the list is not valid JSON", func() {
+			BeforeEach(func() {
+				err := os.WriteFile(deployedPath, []byte(`{"type": "p-bosh"}`), 0644)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns an error", func() {
+				_, err := cmd.CheckDependencies()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(HavePrefix("the deployed products are not a valid JSON list"))
+			})
+		})
+	})
+
+	Context("a version constraint is invalid", func() {
+		BeforeEach(func() {
+			addTile("/tiles/redis-3.0.0.pivotal", "name: p-redis\nrequires_product_versions:\n  - name: cf\n    version: latest\n")
+		})
+
+		It("reports it", func() {
+			report, err := cmd.CheckDependencies()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Unsatisfied).To(HaveLen(1))
+			Expect(report.Unsatisfied[0].Problem).To(Equal(`invalid version constraint: "latest": invalid version: "latest"`))
+		})
+	})
+
+	Context("metadata fails to load", func() {
+		BeforeEach(func() {
+			metadataCmd := addTile("/tiles/broken.pivotal", "")
+			metadataCmd.LoadMetadataStub = nil
+			metadataCmd.LoadMetadataReturns(errors.New("metadata-error"))
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.CheckDependencies()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to load metadata from the tile: /tiles/broken.pivotal: metadata-error"))
+		})
+	})
+})
@@ -0,0 +1,172 @@
+package extract
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/metadata"
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	tileinspect.TileConfig
+	To   string `long:"to" description:"directory to extract the files to, a single file is written to stdout when not set"`
+	Args struct {
+		Patterns []string `positional-arg-name:"glob" description:"files to extract, e.g. metadata/*.yml or migrations" required:"1"`
+	} `positional-args:"yes"`
+}
+
+// matches is true when the pattern matches the name of the file, or one of its directories
+func matches(pattern string, name string) (bool, error) {
+	pattern = strings.TrimSuffix(pattern, "/")
+	for candidate := name; candidate != "." && candidate != "/"; candidate = path.Dir(candidate) {
+		matched, err := path.Match(pattern, candidate)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid pattern %q", pattern)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FindFiles returns the files in the tile that match any of the patterns
+func (cmd *Config) FindFiles(tile *zip.Reader) ([]*zip.File, error) {
+	var found []*zip.File
+	for _, file := range tile.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		for _, pattern := range cmd.Args.Patterns {
+			matched, err := matches(pattern, file.Name)
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				found = append(found, file)
+				break
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		return nil, errors.Errorf("no files in the tile match %s", strings.Join(cmd.Args.Patterns, ", "))
+	}
+	return found, nil
+}
+
+// destination is the path to extract the file to, and refuses paths that would end up outside of the directory
+func destination(dir string, file *zip.File) (string, error) {
+	target := filepath.Join(dir, filepath.FromSlash(file.Name))
+	relative, err := filepath.Rel(dir, target)
+	if err != nil || relative == "." || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("refusing to extract %s outside of %s", file.Name, dir)
+	}
+	return target, nil
+}
+
+func copyFile(file *zip.File, out io.Writer) error {
+	in, err := file.Open()
+	if err != nil {
+		return errors.Wrapf(err, "could not open %s", file.Name)
+	}
+	defer in.Close()
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		return errors.Wrapf(err, "could not extract %s", file.Name)
+	}
+	return nil
+}
+
+func extractFile(file *zip.File, target string) error {
+	err := os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return errors.Wrapf(err, "could not create the directory for %s", target)
+	}
+
+	mode := file.Mode().Perm()
+	if mode == 0 {
+		mode = 0644
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return errors.Wrapf(err, "could not create %s", target)
+	}
+
+	err = copyFile(file, out)
+	if err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	err = out.Close()
+	if err != nil {
+		return errors.Wrapf(err, "could not write %s", target)
+	}
+	return nil
+}
+
+// ExtractTo extracts the matching files into the directory, keeping their paths in the tile
+func (cmd *Config) ExtractTo(dir string, out io.Writer) error {
+	tile, err := metadata.OpenTile(cmd.Tile)
+	if err != nil {
+		return err
+	}
+	defer tile.Close()
+
+	files, err := cmd.FindFiles(&tile.Reader)
+	if err != nil {
+		return err
+	}
+
+	// Check every path before extracting anything
+	targets := make([]string, len(files))
+	for i, file := range files {
+		targets[i], err = destination(dir, file)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, file := range files {
+		err = extractFile(file, targets[i])
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(out, "extracted %s\n", targets[i])
+	}
+	return nil
+}
+
+// WriteFile writes the contents of the single matching file
+func (cmd *Config) WriteFile(out io.Writer) error {
+	tile, err := metadata.OpenTile(cmd.Tile)
+	if err != nil {
+		return err
+	}
+	defer tile.Close()
+
+	files, err := cmd.FindFiles(&tile.Reader)
+	if err != nil {
+		return err
+	}
+	if len(files) > 1 {
+		return errors.Errorf("%d files match, use --to to extract more than one file", len(files))
+	}
+	return copyFile(files[0], out)
+}
+
+func (cmd *Config) Execute(args []string) error {
+	if cmd.To == "" {
+		return cmd.WriteFile(os.Stdout)
+	}
+	return cmd.ExtractTo(cmd.To, os.Stdout)
+}
@@ -0,0 +1,13 @@
+package extract_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestExtract(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Extract Suite")
+}
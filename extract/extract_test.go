@@ -0,0 +1,113 @@
+package extract_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/extract"
+	"github.com/cf-platform-eng/tileinspect/testhelpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Extract", func() {
+	var (
+		cmd    *extract.Config
+		buffer *Buffer
+		dir    string
+	)
+
+	BeforeEach(func() {
+		buffer = NewBuffer()
+		dir = GinkgoT().TempDir()
+		cmd = &extract.Config{
+			TileConfig: tileinspect.TileConfig{
+				Tile: testhelpers.MakeTile(testhelpers.FilesNamed(
+					"metadata/metadata.yml",
+					"migrations/v1/201901010000_first.js",
+					"migrations/v1/201901020000_second.js",
+					"releases/mysql-1.0.0.tgz",
+				)...),
+			},
+		}
+	})
+
+	AfterEach(func() {
+		Expect(buffer.Close()).To(Succeed())
+	})
+
+	Describe("WriteFile", func() {
+		It("writes a single file", func() {
+			cmd.Args.Patterns = []string{"metadata/*.yml"}
+			Expect(cmd.WriteFile(buffer)).To(Succeed())
+			Expect(string(buffer.Contents())).To(Equal("contents of metadata/metadata.yml"))
+		})
+
+		It("returns an error when more than one file matches", func() {
+			cmd.Args.Patterns = []string{"migrations"}
+			err := cmd.WriteFile(buffer)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("2 files match, use --to to extract more than one file"))
+		})
+
+		It("returns an error when no file matches", func() {
+			cmd.Args.Patterns = []string{"embed/*", "releases/cf-*.tgz"}
+			err := cmd.WriteFile(buffer)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("no files in the tile match embed/*, releases/cf-*.tgz"))
+		})
+
+		It("returns an error for an invalid pattern", func() {
+			cmd.Args.Patterns = []string{"releases/["}
+			err := cmd.WriteFile(buffer)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix(`invalid pattern "releases/["`))
+		})
+	})
+
+	Describe("ExtractTo", func() {
+		It("extracts the matching files, keeping their paths", func() {
+			cmd.Args.Patterns = []string{"migrations/", "releases/*.tgz"}
+			Expect(cmd.ExtractTo(dir, buffer)).To(Succeed())
+
+			contents, err := os.ReadFile(filepath.Join(dir, "migrations", "v1", "201901020000_second.js"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("contents of migrations/v1/201901020000_second.js"))
+			Expect(filepath.Join(dir, "migrations", "v1", "201901010000_first.js")).To(BeAnExistingFile())
+			Expect(filepath.Join(dir, "releases", "mysql-1.0.0.tgz")).To(BeAnExistingFile())
+			Expect(filepath.Join(dir, "metadata")).ToNot(BeAnExistingFile())
+
+			Expect(buffer).To(Say("extracted " + filepath.Join(dir, "migrations", "v1", "201901010000_first.js")))
+		})
+
+		Context("the tile has a file outside of the destination", func() {
+			BeforeEach(func() {
+				cmd.Tile = testhelpers.MakeTile(testhelpers.FilesNamed("metadata/metadata.yml", "metadata/../../evil.sh")...)
+			})
+
+			It("refuses to extract anything", func() {
+				cmd.Args.Patterns = []string{"*"}
+				err := cmd.ExtractTo(dir, buffer)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("refusing to extract metadata/../../evil.sh outside of " + dir))
+				Expect(filepath.Join(dir, "metadata", "metadata.yml")).ToNot(BeAnExistingFile())
+				Expect(filepath.Join(filepath.Dir(dir), "evil.sh")).ToNot(BeAnExistingFile())
+			})
+		})
+
+		Context("the tile is not a zip file", func() {
+			BeforeEach(func() {
+				cmd.Tile = "/does/not/exist.pivotal"
+			})
+
+			It("returns an error", func() {
+				cmd.Args.Patterns = []string{"*"}
+				err := cmd.ExtractTo(dir, buffer)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(HavePrefix("could not unzip /does/not/exist.pivotal"))
+			})
+		})
+	})
+})
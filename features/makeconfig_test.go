@@ -55,9 +55,20 @@ var _ = Describe("tileinspect make-config", func() {
 		steps.And("the config file uses the option for the selector that I gave on the cli")
 	})
 
+	Scenario("Tile with a selector and a values file", func() {
+		steps.Given("I have a tile with a selector")
+		steps.And("I have a values file that selects an option")
+
+		steps.When("I run tileinspect make-config with the values file")
+
+		steps.Then("I see the template config file")
+		steps.And("the config file uses the values from the values file")
+	})
+
 	steps.Define(func(define Definitions) {
 		var (
 			tile       *os.File
+			valuesFile *os.File
 			cmd        *exec.Cmd
 			output     []byte
 			configFile *tileinspect.ConfigFile
@@ -68,6 +79,11 @@ var _ = Describe("tileinspect make-config", func() {
 				err := os.Remove(tile.Name())
 				Expect(err).ToNot(HaveOccurred())
 			}
+			if valuesFile != nil {
+				err := os.Remove(valuesFile.Name())
+				Expect(err).ToNot(HaveOccurred())
+				valuesFile = nil
+			}
 		})
 
 		define.Given(`^I have a tile with simple properties$`, func() {
@@ -172,6 +188,30 @@ var _ = Describe("tileinspect make-config", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		define.Given(`^I have a values file that selects an option$`, func() {
+			var err error
+			valuesFile, err = features.MakeConfigFile(heredoc.Doc(`
+			---
+			.properties.continent: Australia
+			.properties.continent.australia.required-string: Sydney
+			`))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		define.When(`^I run tileinspect make-config with the values file$`, func() {
+			cmd = exec.Command("go", "run", "../cmd/tileinspect/main.go", "make-config", "-f", "yaml", "-t", tile.Name(), "--values-file", valuesFile.Name())
+			var err error
+			output, err = cmd.Output()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		define.Then(`^the config file uses the values from the values file$`, func() {
+			Expect(configFile).ToNot(BeNil())
+			Expect(configFile.ProductProperties).ToNot(BeNil())
+			Expect(configFile.ProductProperties[".properties.continent"].Value).To(Equal("Australia"))
+			Expect(configFile.ProductProperties[".properties.continent.australia.required-string"].Value).To(Equal("Sydney"))
+		})
+
 		define.Then(`^I see the template config file$`, func() {
 			err := yaml.Unmarshal(output, &configFile)
 			Expect(err).ToNot(HaveOccurred())
@@ -0,0 +1,226 @@
+package importconfig
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/checkconfig"
+	"github.com/cf-platform-eng/tileinspect/makeconfig"
+	"github.com/cf-platform-eng/tileinspect/metadata"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	tileinspect.TileConfig
+	From string `long:"from" description:"path to the JSON returned by GET /api/v0/staged/products/:guid/properties" required:"true"`
+	//duplicate choice required by go-flags
+	// nolint:staticcheck
+	Format      string `long:"format" short:"f" description:"output file type" choice:"yaml" choice:"json" default:"yaml"`
+	VarsFile    string `long:"vars-file" description:"path to write the template of the variables used for the redacted secrets and credentials"`
+	MetadataCmd tileinspect.MetadataCmd
+
+	variables map[string]interface{}
+}
+
+// StagedProperty is a property as returned by the Ops Manager API
+type StagedProperty struct {
+	Type         string      `json:"type"`
+	Configurable bool        `json:"configurable"`
+	Optional     bool        `json:"optional"`
+	Value        interface{} `json:"value"`
+}
+
+type StagedProperties struct {
+	Properties map[string]*StagedProperty `json:"properties"`
+}
+
+func LoadStagedProperties(path string) (*StagedProperties, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the staged properties: %s", path)
+	}
+
+	stagedProperties := &StagedProperties{}
+	err = json.Unmarshal(contents, stagedProperties)
+	if err != nil {
+		return nil, errors.Wrap(err, "the staged properties are not valid JSON")
+	}
+	if stagedProperties.Properties == nil {
+		return nil, errors.New("the staged properties do not contain a properties section")
+	}
+
+	return stagedProperties, nil
+}
+
+// unselectedProperties finds the property keys that belong to selector options that are not selected
+func unselectedProperties(tileProperties *tileinspect.TileProperties, staged map[string]*StagedProperty) map[string]bool {
+	unselected := make(map[string]bool)
+	tileProperties.WalkBlueprints(func(propertyKey string, property tileinspect.TileProperty, options []tileinspect.SelectorOption) {
+		for _, option := range options {
+			selectedValue := option.Selector.Default
+			if stagedProperty, ok := staged[option.SelectorKey]; ok && stagedProperty.Value != nil {
+				selectedValue = stagedProperty.Value
+			}
+			if selectedValue != option.Option.SelectValue {
+				unselected[propertyKey] = true
+				return
+			}
+		}
+	})
+	return unselected
+}
+
+// normalize makes values from the tile and from the API comparable, e.g. integers become float64
+func normalize(value interface{}) interface{} {
+	bytes, err := json.Marshal(value)
+	if err != nil { // !branch-not-tested No good way to force this
+		return value
+	}
+
+	var normalized interface{}
+	err = json.Unmarshal(bytes, &normalized)
+	if err != nil { // !branch-not-tested No good way to force this
+		return value
+	}
+	return normalized
+}
+
+func isDefault(property tileinspect.TileProperty, value interface{}) bool {
+	return property.Default != nil && reflect.DeepEqual(normalize(property.Default), normalize(value))
+}
+
+// collectionValue unwraps the collection items, each field of an item is a staged property itself
+func collectionValue(value interface{}) interface{} {
+	items, ok := value.([]interface{})
+	if !ok {
+		return value
+	}
+
+	var result []interface{}
+	for _, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			result = append(result, item)
+			continue
+		}
+
+		values := make(map[string]interface{})
+		for name, field := range fields {
+			stagedField, ok := field.(map[string]interface{})
+			if !ok {
+				values[name] = field
+				continue
+			}
+			if configurable, _ := stagedField["configurable"].(bool); !configurable {
+				continue
+			}
+			if stagedField["value"] != nil {
+				values[name] = stagedField["value"]
+			}
+		}
+		result = append(result, values)
+	}
+	return result
+}
+
+func (cmd *Config) ImportConfig() (*tileinspect.ConfigFile, error) {
+	stagedProperties, err := LoadStagedProperties(cmd.From)
+	if err != nil {
+		return nil, err
+	}
+
+	tileProperties := &tileinspect.TileProperties{}
+	err = cmd.MetadataCmd.LoadMetadata(tileProperties)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load metadata from the tile")
+	}
+
+	blueprints := tileProperties.Blueprints()
+	unselected := unselectedProperties(tileProperties, stagedProperties.Properties)
+
+	config := &tileinspect.ConfigFile{
+		ProductName:       tileProperties.Name,
+		ProductProperties: make(map[string]*tileinspect.ConfigFileProperty),
+	}
+	for propertyKey, stagedProperty := range stagedProperties.Properties {
+		if stagedProperty == nil || !stagedProperty.Configurable || stagedProperty.Value == nil || unselected[propertyKey] {
+			continue
+		}
+
+		value := stagedProperty.Value
+		if stagedProperty.Type == "collection" {
+			value = collectionValue(value)
+		}
+
+		if blueprint, ok := blueprints[propertyKey]; ok && isDefault(blueprint, value) {
+			continue
+		}
+		config.ProductProperties[propertyKey] = &tileinspect.ConfigFileProperty{
+			Type:  stagedProperty.Type,
+			Value: value,
+		}
+	}
+
+	// Ops Manager does not return the values of secrets and credentials
+	cmd.variables = makeconfig.ReplaceSecretsWithVariables(config, tileProperties)
+
+	check := &checkconfig.Config{}
+	errs := check.CompareProperties(config, tileProperties)
+	if len(errs) > 0 {
+		return nil, errors.Errorf("the imported config file is not valid for this tile:\n%s\n", checkconfig.JoinErrors(errs))
+	}
+
+	return config, nil
+}
+
+// Variables returns the variables used for the secrets and credentials of the last imported config file
+func (cmd *Config) Variables() map[string]interface{} {
+	return cmd.variables
+}
+
+func (cmd *Config) WriteConfig(out io.Writer, config *tileinspect.ConfigFile) error {
+	var bytes []byte
+	var err error
+	if cmd.Format == "json" {
+		bytes, err = json.Marshal(config)
+	} else {
+		bytes, err = yaml.Marshal(config)
+	}
+	if err != nil { // !branch-not-tested No good way to force this
+		return errors.Wrap(err, "failed to convert config file")
+	}
+
+	_, err = out.Write(bytes)
+	if err != nil {
+		return errors.Wrap(err, "failed to print config file")
+	}
+
+	return nil
+}
+
+func (cmd *Config) Execute(args []string) error {
+	cmd.MetadataCmd = &metadata.Config{
+		TileConfig: tileinspect.TileConfig{
+			Tile: cmd.Tile,
+		},
+	}
+
+	config, err := cmd.ImportConfig()
+	if err != nil {
+		return err
+	}
+
+	if cmd.VarsFile != "" {
+		vars := &makeconfig.Config{}
+		err = vars.WriteVarsFile(cmd.VarsFile, cmd.variables)
+		if err != nil {
+			return err
+		}
+	}
+
+	return cmd.WriteConfig(os.Stdout, config)
+}
@@ -0,0 +1,13 @@
+package importconfig_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestImportConfig(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ImportConfig Suite")
+}
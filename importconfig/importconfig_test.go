@@ -0,0 +1,226 @@
+package importconfig_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect/importconfig"
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("ImportConfig", func() {
+	var (
+		cmd         *importconfig.Config
+		metadataCmd *tileinspectfakes.FakeMetadataCmd
+		stagedPath  string
+	)
+
+	writeStagedProperties := func(contents string) {
+		err := os.WriteFile(stagedPath, []byte(contents), 0644)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		stagedPath = filepath.Join(GinkgoT().TempDir(), "staged-properties.json")
+
+		metadataCmd = &tileinspectfakes.FakeMetadataCmd{}
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			name: product
+			property_blueprints:
+			  - name: hostname
+			    type: string
+			    configurable: true
+			  - name: max_conns
+			    type: integer
+			    configurable: true
+			    default: 10
+			  - name: internal
+			    type: string
+			  - name: admin-password
+			    type: secret
+			    configurable: true
+			  - name: continent
+			    type: selector
+			    configurable: true
+			    default: North America
+			    option_templates:
+			      - name: north-america
+			        select_value: North America
+			        property_blueprints:
+			          - name: city
+			            type: string
+			            configurable: true
+			      - name: australia
+			        select_value: Australia
+			        property_blueprints:
+			          - name: region
+			            type: string
+			            configurable: true
+			  - name: users
+			    type: collection
+			    configurable: true
+			    optional: true
+			    property_blueprints:
+			      - name: name
+			        type: string
+			        configurable: true
+			      - name: password
+			        type: secret
+			        configurable: true
+			`)), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+
+		cmd = &importconfig.Config{
+			From:        stagedPath,
+			Format:      "yaml",
+			MetadataCmd: metadataCmd,
+		}
+
+		writeStagedProperties(heredoc.Doc(`{
+		  "properties": {
+		    ".properties.hostname": {"type": "string", "configurable": true, "credential": false, "optional": false, "value": "example.com"},
+		    ".properties.max_conns": {"type": "integer", "configurable": true, "credential": false, "optional": false, "value": 10},
+		    ".properties.internal": {"type": "string", "configurable": false, "credential": false, "optional": false, "value": "hidden"},
+		    ".properties.admin-password": {"type": "secret", "configurable": true, "credential": true, "optional": false, "value": {"secret": "***"}},
+		    ".properties.continent": {"type": "selector", "configurable": true, "credential": false, "optional": false, "value": "Australia", "selected_option": "australia"},
+		    ".properties.continent.north-america.city": {"type": "string", "configurable": true, "credential": false, "optional": false, "value": "Toronto"},
+		    ".properties.continent.australia.region": {"type": "string", "configurable": true, "credential": false, "optional": false, "value": "west"},
+		    ".properties.users": {"type": "collection", "configurable": true, "credential": false, "optional": true, "value": [
+		      {
+		        "guid": {"type": "uuid", "configurable": false, "credential": false, "optional": false, "value": "1234"},
+		        "name": {"type": "string", "configurable": true, "credential": false, "optional": false, "value": "alice"},
+		        "password": {"type": "secret", "configurable": true, "credential": true, "optional": false, "value": {"secret": "***"}}
+		      }
+		    ]}
+		  }
+		}`))
+	})
+
+	It("converts the staged properties into a config file", func() {
+		config, err := cmd.ImportConfig()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(config.ProductName).To(Equal("product"))
+		Expect(config.ProductProperties).To(HaveLen(5))
+		Expect(config.ProductProperties[".properties.hostname"].Value).To(Equal("example.com"))
+		Expect(config.ProductProperties[".properties.continent"].Value).To(Equal("Australia"))
+		Expect(config.ProductProperties[".properties.continent.australia.region"].Value).To(Equal("west"))
+		Expect(config.ProductProperties[".properties.users"].Value).To(Equal([]interface{}{
+			map[string]interface{}{"name": "alice", "password": map[string]interface{}{"secret": "((properties_users_0_password))"}},
+		}))
+	})
+
+	It("skips unconfigurable properties, default values and properties of unselected options", func() {
+		config, err := cmd.ImportConfig()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(config.ProductProperties).ToNot(HaveKey(".properties.internal"))
+		Expect(config.ProductProperties).ToNot(HaveKey(".properties.max_conns"))
+		Expect(config.ProductProperties).ToNot(HaveKey(".properties.continent.north-america.city"))
+	})
+
+	It("replaces the redacted secrets with variables", func() {
+		config, err := cmd.ImportConfig()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(config.ProductProperties[".properties.admin-password"].Value).To(Equal(map[string]interface{}{"secret": "((properties_admin_password))"}))
+		Expect(cmd.Variables()).To(Equal(map[string]interface{}{
+			"properties_admin_password":   "",
+			"properties_users_0_password": "",
+		}))
+	})
+
+	It("writes the config file", func() {
+		config, err := cmd.ImportConfig()
+		Expect(err).ToNot(HaveOccurred())
+
+		buffer := NewBuffer()
+		defer buffer.Close()
+		err = cmd.WriteConfig(buffer, config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(buffer.Contents())).To(ContainSubstring("  .properties.hostname:\n    type: string\n    value: example.com\n"))
+	})
+
+	Context("the staged properties are missing a required property", func() {
+		BeforeEach(func() {
+			writeStagedProperties(`{"properties": {".properties.hostname": {"type": "string", "configurable": true, "value": null}}}`)
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.ImportConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("the imported config file is not valid for this tile:"))
+			Expect(err.Error()).To(ContainSubstring("the config file is missing a required property (.properties.continent.north-america.city)"))
+		})
+	})
+
+	Context("the staged properties contain a property that is not in the tile", func() {
+		BeforeEach(func() {
+			writeStagedProperties(`{"properties": {".properties.other": {"type": "string", "configurable": true, "value": "x"}}}`)
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.ImportConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(".properties.other"))
+		})
+	})
+
+	Context("the staged properties are not valid JSON", func() {
+		BeforeEach(func() {
+			writeStagedProperties("this is not json")
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.ImportConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("the staged properties are not valid JSON"))
+		})
+	})
+
+	Context("the staged properties do not have a properties section", func() {
+		BeforeEach(func() {
+			writeStagedProperties("{}")
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.ImportConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("the staged properties do not contain a properties section"))
+		})
+	})
+
+	Context("the staged properties file does not exist", func() {
+		BeforeEach(func() {
+			cmd.From = "/does/not/exist.json"
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.ImportConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("failed to read the staged properties: /does/not/exist.json"))
+		})
+	})
+
+	Context("metadata fails to load", func() {
+		BeforeEach(func() {
+			metadataCmd.LoadMetadataReturns(errors.New("metadata-error"))
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.ImportConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to load metadata from the tile: metadata-error"))
+		})
+	})
+})
@@ -0,0 +1,183 @@
+package ls
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/metadata"
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	tileinspect.TileConfig
+	// duplicate choice required by go-flags
+	// nolint:staticcheck
+	Sort string `long:"sort" description:"sort the directories and entries by name or by size, largest first" choice:"name" choice:"size" default:"name"`
+	// nolint:staticcheck
+	Format string `long:"format" short:"f" description:"output format" choice:"text" choice:"json" default:"text"`
+}
+
+type Entry struct {
+	Name              string `json:"name"`
+	Size              uint64 `json:"size"`
+	CompressedSize    uint64 `json:"compressed_size"`
+	CompressionMethod string `json:"compression_method"`
+}
+
+// Directory is a top-level directory of the tile, e.g. metadata or releases, with the total size of its entries
+type Directory struct {
+	Name           string   `json:"name"`
+	Size           uint64   `json:"size"`
+	CompressedSize uint64   `json:"compressed_size"`
+	Entries        []*Entry `json:"entries"`
+}
+
+type Listing struct {
+	Size           uint64       `json:"size"`
+	CompressedSize uint64       `json:"compressed_size"`
+	Directories    []*Directory `json:"directories"`
+}
+
+// topLevel is the name used for the entries that are not in a directory
+const topLevel = "."
+
+func compressionMethod(method uint16) string {
+	switch method {
+	case zip.Store:
+		return "store"
+	case zip.Deflate:
+		return "deflate"
+	}
+	return fmt.Sprintf("method %d", method)
+}
+
+func (cmd *Config) sortBySize() bool {
+	return cmd.Sort == "size"
+}
+
+// ListTile lists the entries of the tile by top-level directory
+func (cmd *Config) ListTile() (*Listing, error) {
+	tile, err := metadata.OpenTile(cmd.Tile)
+	if err != nil {
+		return nil, err
+	}
+	defer tile.Close()
+
+	listing := &Listing{Directories: []*Directory{}}
+	directories := make(map[string]*Directory)
+	for _, file := range tile.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		name := topLevel
+		if index := strings.Index(file.Name, "/"); index >= 0 {
+			name = file.Name[:index]
+		}
+		directory, ok := directories[name]
+		if !ok {
+			directory = &Directory{Name: name}
+			directories[name] = directory
+			listing.Directories = append(listing.Directories, directory)
+		}
+
+		directory.Entries = append(directory.Entries, &Entry{
+			Name:              file.Name,
+			Size:              file.UncompressedSize64,
+			CompressedSize:    file.CompressedSize64,
+			CompressionMethod: compressionMethod(file.Method),
+		})
+		directory.Size += file.UncompressedSize64
+		directory.CompressedSize += file.CompressedSize64
+		listing.Size += file.UncompressedSize64
+		listing.CompressedSize += file.CompressedSize64
+	}
+
+	sort.SliceStable(listing.Directories, func(i, j int) bool {
+		a, b := listing.Directories[i], listing.Directories[j]
+		if cmd.sortBySize() && a.Size != b.Size {
+			return a.Size > b.Size
+		}
+		return a.Name < b.Name
+	})
+	for _, directory := range listing.Directories {
+		entries := directory.Entries
+		sort.SliceStable(entries, func(i, j int) bool {
+			if cmd.sortBySize() && entries[i].Size != entries[j].Size {
+				return entries[i].Size > entries[j].Size
+			}
+			return entries[i].Name < entries[j].Name
+		})
+	}
+	return listing, nil
+}
+
+// HumanSize formats a number of bytes, e.g. 1.5 GB
+func HumanSize(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	size := float64(bytes) / unit
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		if size < unit {
+			return fmt.Sprintf("%.1f %s", size, suffix)
+		}
+		size /= unit
+	}
+	return fmt.Sprintf("%.1f TB", size)
+}
+
+// ratio is the compressed size as a percentage of the size
+func ratio(size, compressedSize uint64) string {
+	if size == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", float64(compressedSize)*100/float64(size))
+}
+
+func (listing *Listing) Write(out io.Writer) error {
+	writer := tabwriter.NewWriter(out, 0, 4, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintln(writer, "SIZE\tCOMPRESSED\tRATIO\tMETHOD\t NAME")
+	for _, directory := range listing.Directories {
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t\t %s/\n", HumanSize(directory.Size), HumanSize(directory.CompressedSize), ratio(directory.Size, directory.CompressedSize), directory.Name)
+		for _, entry := range directory.Entries {
+			_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t   %s\n", HumanSize(entry.Size), HumanSize(entry.CompressedSize), ratio(entry.Size, entry.CompressedSize), entry.CompressionMethod, entry.Name)
+		}
+	}
+	_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t\t total\n", HumanSize(listing.Size), HumanSize(listing.CompressedSize), ratio(listing.Size, listing.CompressedSize))
+
+	err := writer.Flush()
+	if err != nil {
+		return errors.Wrap(err, "failed to print the listing")
+	}
+	return nil
+}
+
+func (cmd *Config) WriteListing(out io.Writer) error {
+	listing, err := cmd.ListTile()
+	if err != nil {
+		return err
+	}
+
+	if cmd.Format != "json" {
+		return listing.Write(out)
+	}
+
+	err = json.NewEncoder(out).Encode(listing)
+	if err != nil { // !branch-not-tested No good way to force this
+		return errors.Wrap(err, "failed to encode the listing")
+	}
+	return nil
+}
+
+func (cmd *Config) Execute(args []string) error {
+	return cmd.WriteListing(os.Stdout)
+}
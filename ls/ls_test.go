@@ -0,0 +1,129 @@
+package ls_test
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"strings"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/ls"
+	"github.com/cf-platform-eng/tileinspect/testhelpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+func makeTile() string {
+	return testhelpers.MakeTile(
+		testhelpers.TileFile{Name: "metadata/"},
+		testhelpers.TileFile{Name: "metadata/metadata.yml", Contents: []byte(strings.Repeat("name: product\n", 100)), Method: zip.Deflate},
+		testhelpers.TileFile{Name: "releases/small.tgz", Contents: []byte("small release")},
+		testhelpers.TileFile{Name: "releases/large.tgz", Contents: []byte(strings.Repeat("large release", 100))},
+		testhelpers.TileFile{Name: "migrations/v1/201901010000_migration.js", Contents: []byte("migration"), Method: zip.Deflate},
+		testhelpers.TileFile{Name: "README", Contents: []byte("readme")},
+	)
+}
+
+var _ = Describe("ListTile", func() {
+	var cmd *ls.Config
+
+	BeforeEach(func() {
+		cmd = &ls.Config{
+			TileConfig: tileinspect.TileConfig{Tile: makeTile()},
+			Sort:       "name",
+			Format:     "text",
+		}
+	})
+
+	It("groups the entries by top-level directory", func() {
+		listing, err := cmd.ListTile()
+		Expect(err).ToNot(HaveOccurred())
+
+		var names []string
+		for _, directory := range listing.Directories {
+			names = append(names, directory.Name)
+		}
+		Expect(names).To(Equal([]string{".", "metadata", "migrations", "releases"}))
+
+		releases := listing.Directories[3]
+		Expect(releases.Entries).To(HaveLen(2))
+		Expect(releases.Entries[0].Name).To(Equal("releases/large.tgz"))
+		Expect(releases.Entries[0].Size).To(Equal(uint64(1300)))
+		Expect(releases.Entries[0].CompressedSize).To(Equal(uint64(1300)))
+		Expect(releases.Entries[0].CompressionMethod).To(Equal("store"))
+		Expect(releases.Size).To(Equal(uint64(1313)))
+
+		metadata := listing.Directories[1]
+		Expect(metadata.Entries).To(HaveLen(1))
+		Expect(metadata.Entries[0].CompressionMethod).To(Equal("deflate"))
+		Expect(metadata.Entries[0].CompressedSize).To(BeNumerically("<", metadata.Entries[0].Size))
+
+		Expect(listing.Size).To(Equal(uint64(1400 + 1313 + 9 + 6)))
+	})
+
+	Context("sorting by size", func() {
+		BeforeEach(func() {
+			cmd.Sort = "size"
+		})
+
+		It("puts the largest directories and entries first", func() {
+			listing, err := cmd.ListTile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listing.Directories[1].Name).To(Equal("releases"))
+			Expect(listing.Directories[1].Entries[0].Name).To(Equal("releases/large.tgz"))
+			Expect(listing.Directories[1].Entries[1].Name).To(Equal("releases/small.tgz"))
+		})
+
+		It("uses the size, not the compressed size", func() {
+			listing, err := cmd.ListTile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listing.Directories[0].Name).To(Equal("metadata"))
+			Expect(listing.Directories[0].CompressedSize).To(BeNumerically("<", listing.Directories[1].CompressedSize))
+		})
+	})
+
+	It("prints a table", func() {
+		buffer := NewBuffer()
+		defer buffer.Close()
+		Expect(cmd.WriteListing(buffer)).To(Succeed())
+
+		Expect(buffer).To(Say(`SIZE\s+COMPRESSED\s+RATIO\s+METHOD\s+NAME`))
+		Expect(buffer).To(Say(`6 B\s+6 B\s+100%\s+\./`))
+		Expect(buffer).To(Say(`6 B\s+6 B\s+100%\s+store\s+README`))
+		Expect(buffer).To(Say(`1.3 KB\s+1.3 KB\s+100%\s+store\s+releases/large.tgz`))
+		Expect(buffer).To(Say(`2.7 KB\s+.*\s+total`))
+	})
+
+	It("prints JSON", func() {
+		cmd.Format = "json"
+		buffer := NewBuffer()
+		defer buffer.Close()
+		Expect(cmd.WriteListing(buffer)).To(Succeed())
+
+		listing := &ls.Listing{}
+		Expect(json.Unmarshal(buffer.Contents(), listing)).To(Succeed())
+		Expect(listing.Directories).To(HaveLen(4))
+		Expect(listing.Directories[2].Entries[0].Name).To(Equal("migrations/v1/201901010000_migration.js"))
+	})
+
+	Context("the tile is not a zip file", func() {
+		BeforeEach(func() {
+			cmd.Tile = "/does/not/exist.pivotal"
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.ListTile()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("could not unzip /does/not/exist.pivotal"))
+		})
+	})
+})
+
+var _ = Describe("HumanSize", func() {
+	It("formats sizes", func() {
+		Expect(ls.HumanSize(512)).To(Equal("512 B"))
+		Expect(ls.HumanSize(1536)).To(Equal("1.5 KB"))
+		Expect(ls.HumanSize(20 * 1024 * 1024 * 1024)).To(Equal("20.0 GB"))
+		Expect(ls.HumanSize(3 * 1024 * 1024 * 1024 * 1024)).To(Equal("3.0 TB"))
+	})
+})
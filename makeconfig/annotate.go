@@ -0,0 +1,147 @@
+package makeconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/ghodss/yaml"
+)
+
+func describeOptions(propertyKey string, property tileinspect.TileProperty, inputs map[string]tileinspect.PropertyInput) []string {
+	var options []string
+	if property.Type == "dropdown_select" {
+		for _, option := range property.Options {
+			if option.Label != nil && fmt.Sprint(option.Label) != fmt.Sprint(option.Name) {
+				options = append(options, fmt.Sprintf("%v (%v)", option.Name, option.Label))
+			} else {
+				options = append(options, fmt.Sprint(option.Name))
+			}
+		}
+	} else if property.Type == "selector" {
+		for _, option := range property.ChildProperties {
+			label := inputs[propertyKey+"."+option.Name].Label
+			if label != "" && label != option.SelectValue {
+				options = append(options, fmt.Sprintf("%s (%s)", option.SelectValue, label))
+			} else {
+				options = append(options, option.SelectValue)
+			}
+		}
+	}
+	return options
+}
+
+func (cmd *Config) describeProperty(propertyKey string, blueprints map[string]tileinspect.TileProperty, inputs map[string]tileinspect.PropertyInput) []string {
+	var lines []string
+
+	input := inputs[propertyKey]
+	if input.Label != "" {
+		lines = append(lines, "Label: "+input.Label)
+	}
+	if description := strings.TrimSpace(input.Description); description != "" {
+		for i, line := range strings.Split(description, "\n") {
+			if i == 0 {
+				lines = append(lines, "Description: "+strings.TrimSpace(line))
+			} else {
+				lines = append(lines, "  "+strings.TrimSpace(line))
+			}
+		}
+	}
+
+	property, ok := blueprints[propertyKey]
+	if ok {
+		lines = append(lines, "Type: "+property.Type)
+	}
+	if source, ok := cmd.valueSources[propertyKey]; ok {
+		lines = append(lines, "Value: "+string(source))
+	}
+
+	options := describeOptions(propertyKey, property, inputs)
+	if len(options) > 0 {
+		lines = append(lines, "Options:")
+		for _, option := range options {
+			lines = append(lines, "  - "+option)
+		}
+	}
+
+	return lines
+}
+
+func indent(text []byte, prefix string) []byte {
+	lines := bytes.Split(bytes.TrimRight(text, "\n"), []byte("\n"))
+	var result bytes.Buffer
+	for _, line := range lines {
+		if len(line) > 0 {
+			result.WriteString(prefix)
+		}
+		result.Write(line)
+		result.WriteString("\n")
+	}
+	return result.Bytes()
+}
+
+func (cmd *Config) annotateConfig(config *tileinspect.ConfigFile) ([]byte, error) {
+	blueprints := make(map[string]tileinspect.TileProperty)
+	inputs := make(map[string]tileinspect.PropertyInput)
+	if cmd.tileProperties != nil {
+		blueprints = cmd.tileProperties.Blueprints()
+		inputs = cmd.tileProperties.PropertyInputs()
+	}
+
+	var out bytes.Buffer
+	productName, err := yaml.Marshal(map[string]string{"product-name": config.ProductName})
+	if err != nil { // !branch-not-tested No good way to force this
+		return nil, err
+	}
+	out.Write(productName)
+
+	if len(config.ProductProperties) == 0 {
+		out.WriteString("product-properties: {}\n")
+	} else {
+		out.WriteString("product-properties:\n")
+	}
+
+	keys := make([]string, 0, len(config.ProductProperties))
+	for key := range config.ProductProperties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, line := range cmd.describeProperty(key, blueprints, inputs) {
+			out.WriteString("  # " + line + "\n")
+		}
+
+		property, err := yaml.Marshal(map[string]*tileinspect.ConfigFileProperty{key: config.ProductProperties[key]})
+		if err != nil {
+			return nil, err
+		}
+		out.Write(indent(property, "  "))
+	}
+
+	// The remaining sections are written without annotations
+	sections, err := json.Marshal(config)
+	if err != nil { // !branch-not-tested No good way to force this
+		return nil, err
+	}
+	var remaining map[string]interface{}
+	err = json.Unmarshal(sections, &remaining)
+	if err != nil { // !branch-not-tested No good way to force this
+		return nil, err
+	}
+	delete(remaining, "product-name")
+	delete(remaining, "product-properties")
+
+	if len(remaining) > 0 {
+		rest, err := yaml.Marshal(remaining)
+		if err != nil { // !branch-not-tested No good way to force this
+			return nil, err
+		}
+		out.Write(rest)
+	}
+
+	return out.Bytes(), nil
+}
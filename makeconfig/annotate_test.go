@@ -0,0 +1,184 @@
+package makeconfig_test
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/makeconfig"
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("WriteConfig with annotations", func() {
+	var (
+		buffer      *Buffer
+		cmd         *makeconfig.Config
+		metadataCmd *tileinspectfakes.FakeMetadataCmd
+	)
+
+	BeforeEach(func() {
+		buffer = NewBuffer()
+		metadataCmd = &tileinspectfakes.FakeMetadataCmd{}
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			name: product
+			property_blueprints:
+			  - name: hostname
+			    type: string
+			    configurable: true
+			  - name: max_conns
+			    type: integer
+			    configurable: true
+			    default: 10
+			  - name: fruit
+			    type: dropdown_select
+			    configurable: true
+			    options:
+			      - name: kiwi
+			        label: Kiwi fruit
+			      - name: lime
+			        label: lime
+			  - name: continent
+			    type: selector
+			    configurable: true
+			    option_templates:
+			      - name: north-america
+			        select_value: North America
+			        property_blueprints:
+			          - name: city
+			            type: string
+			            configurable: true
+			      - name: australia
+			        select_value: Australia
+			        property_blueprints: []
+			form_types:
+			  - name: config
+			    label: Configuration
+			    property_inputs:
+			      - reference: .properties.hostname
+			        label: Hostname
+			        description: |
+			          The hostname of the server.
+			          Must be reachable from the apps.
+			      - reference: .properties.continent
+			        label: Continent
+			        selector_property_inputs:
+			          - reference: .properties.continent.north-america
+			            label: North America, the continent
+			            property_inputs:
+			              - reference: .properties.continent.north-america.city
+			                label: City
+			job_types:
+			  - name: server
+            `)), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+
+		cmd = &makeconfig.Config{
+			Format:      "yaml",
+			Annotate:    true,
+			MetadataCmd: metadataCmd,
+			Values: map[string]string{
+				".properties.fruit": "lime",
+			},
+		}
+	})
+
+	AfterEach(func() {
+		err := buffer.Close()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("writes comments for each property", func() {
+		config, err := cmd.MakeConfig()
+		Expect(err).ToNot(HaveOccurred())
+
+		err = cmd.WriteConfig(buffer, config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(buffer.Contents())).To(Equal(heredoc.Doc(`
+			product-name: product
+			product-properties:
+			  # Label: Continent
+			  # Type: selector
+			  # Value: first option
+			  # Options:
+			  #   - North America (North America, the continent)
+			  #   - Australia
+			  .properties.continent:
+			    type: selector
+			    value: North America
+			  # Label: City
+			  # Type: string
+			  # Value: sample value, replace before use
+			  .properties.continent.north-america.city:
+			    type: string
+			    value: SAMPLE_STRING_VALUE
+			  # Type: dropdown_select
+			  # Value: given value
+			  # Options:
+			  #   - kiwi (Kiwi fruit)
+			  #   - lime
+			  .properties.fruit:
+			    type: dropdown_select
+			    value: lime
+			  # Label: Hostname
+			  # Description: The hostname of the server.
+			  #   Must be reachable from the apps.
+			  # Type: string
+			  # Value: sample value, replace before use
+			  .properties.hostname:
+			    type: string
+			    value: SAMPLE_STRING_VALUE
+			  # Type: integer
+			  # Value: tile default
+			  .properties.max_conns:
+			    type: integer
+			    value: 10
+			network-properties:
+			  network:
+			    name: SAMPLE_NETWORK_NAME
+			  other_availability_zones:
+			  - name: SAMPLE_AVAILABILITY_ZONE_NAME
+			  singleton_availability_zone:
+			    name: SAMPLE_AVAILABILITY_ZONE_NAME
+			resource-config:
+			  server:
+			    instance_type:
+			      id: automatic
+			    instances: automatic
+			`)))
+	})
+
+	It("is still a valid config file", func() {
+		config, err := cmd.MakeConfig()
+		Expect(err).ToNot(HaveOccurred())
+
+		err = cmd.WriteConfig(buffer, config)
+		Expect(err).ToNot(HaveOccurred())
+
+		parsed := &tileinspect.ConfigFile{}
+		err = yaml.Unmarshal(buffer.Contents(), parsed)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed.ProductProperties).To(HaveLen(5))
+		Expect(parsed.ProductProperties[".properties.max_conns"].Value).To(BeEquivalentTo(10))
+	})
+
+	Context("json format", func() {
+		BeforeEach(func() {
+			cmd.Format = "json"
+		})
+
+		It("returns an error", func() {
+			config, err := cmd.MakeConfig()
+			Expect(err).ToNot(HaveOccurred())
+
+			err = cmd.WriteConfig(buffer, config)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("annotations are only supported for the yaml format"))
+		})
+	})
+})
@@ -0,0 +1,79 @@
+package makeconfig
+
+import (
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/checkconfig"
+)
+
+func removeInvalidProperties(config *tileinspect.ConfigFile, tileProperties *tileinspect.TileProperties) {
+	check := &checkconfig.Config{}
+
+	// Removing a property (e.g. a selector) can make other properties invalid, so repeat until nothing changes
+	for {
+		removed := false
+		for _, err := range check.CompareProperties(config, tileProperties) {
+			propertyError, ok := err.(*checkconfig.PropertyError)
+			if !ok || propertyError.Missing {
+				continue
+			}
+
+			if _, ok := config.ProductProperties[propertyError.Key]; ok {
+				delete(config.ProductProperties, propertyError.Key)
+				removed = true
+			}
+		}
+
+		if !removed {
+			return
+		}
+	}
+}
+
+func (cmd *Config) loadBaseConfig(tileProperties *tileinspect.TileProperties) (*tileinspect.ConfigFile, error) {
+	base, err := checkconfig.LoadConfigFile(cmd.Base)
+	if err != nil {
+		return nil, err
+	}
+
+	base.ProductName = tileProperties.Name
+	if base.ProductProperties == nil {
+		base.ProductProperties = make(map[string]*tileinspect.ConfigFileProperty)
+	}
+
+	for key, property := range base.ProductProperties {
+		if property == nil {
+			delete(base.ProductProperties, key)
+		}
+	}
+
+	for key := range cmd.valueOverrides {
+		delete(base.ProductProperties, key)
+	}
+
+	// Fill in the overridden values first, so that invalid values are found using the selector options that will be chosen
+	err = cmd.setValuesForTile(base, tileProperties)
+	if err != nil {
+		return nil, err
+	}
+
+	removeInvalidProperties(base, tileProperties)
+	return base, nil
+}
+
+func applyBaseSections(config *tileinspect.ConfigFile, base *tileinspect.ConfigFile) {
+	if base.NetworkProperties != nil {
+		config.NetworkProperties = base.NetworkProperties
+	}
+
+	for jobName := range config.ResourceConfig {
+		if resource := base.ResourceConfig[jobName]; resource != nil {
+			config.ResourceConfig[jobName] = resource
+		}
+	}
+
+	for errandName := range config.ErrandConfig {
+		if errand := base.ErrandConfig[errandName]; errand != nil {
+			config.ErrandConfig[errandName] = errand
+		}
+	}
+}
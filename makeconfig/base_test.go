@@ -0,0 +1,187 @@
+package makeconfig_test
+
+import (
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect/makeconfig"
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MakeConfig with a base config file", func() {
+	var (
+		cmd         *makeconfig.Config
+		metadataCmd *tileinspectfakes.FakeMetadataCmd
+		baseFile    *os.File
+	)
+
+	BeforeEach(func() {
+		metadataCmd = &tileinspectfakes.FakeMetadataCmd{}
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			name: product
+			property_blueprints:
+			  - name: hostname
+			    type: string
+			    configurable: true
+			  - name: new-property
+			    type: string
+			    configurable: true
+			  - name: now-locked
+			    type: string
+			    configurable: false
+			  - name: flavor
+			    type: dropdown_select
+			    configurable: true
+			    options:
+			      - name: vanilla
+			      - name: chocolate
+			  - name: continent
+			    type: selector
+			    configurable: true
+			    option_templates:
+			      - name: north-america
+			        select_value: North America
+			        property_blueprints:
+			          - name: required-string
+			            type: string
+			            configurable: true
+			      - name: australia
+			        select_value: Australia
+			        property_blueprints:
+			          - name: required-string
+			            type: string
+			            configurable: true
+			          - name: new-string
+			            type: string
+			            configurable: true
+			job_types:
+			  - name: server
+			    instance_definition:
+			      name: instances
+			      type: integer
+			      configurable: true
+			      default: 1
+			  - name: new-job
+			    instance_definition:
+			      name: instances
+			      type: integer
+			      configurable: true
+			      default: 2
+            `)), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+
+		var err error
+		baseFile, err = makeValuesFile(heredoc.Doc(`
+		---
+		product-name: product
+		product-properties:
+		  .properties.hostname:
+		    value: my-host
+		  .properties.removed-property:
+		    value: gone
+		  .properties.now-locked:
+		    value: locked
+		  .properties.flavor:
+		    value: strawberry
+		  .properties.continent:
+		    value: Australia
+		  .properties.continent.australia.required-string:
+		    value: Sydney
+		  .properties.continent.north-america.required-string:
+		    value: Toronto
+		network-properties:
+		  network:
+		    name: my-network
+		  other_availability_zones:
+		    - name: az1
+		  singleton_availability_zone:
+		    name: az1
+		resource-config:
+		  server:
+		    instances: 5
+		    instance_type:
+		      id: large
+		  removed-job:
+		    instances: 1
+		    instance_type:
+		      id: automatic
+		`))
+		Expect(err).ToNot(HaveOccurred())
+
+		cmd = &makeconfig.Config{
+			MetadataCmd: metadataCmd,
+			Base:        baseFile.Name(),
+		}
+	})
+
+	AfterEach(func() {
+		err := os.Remove(baseFile.Name())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("keeps the valid values and fills in the missing ones", func() {
+		config, err := cmd.MakeConfig()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.ProductName).To(Equal("product"))
+
+		Expect(config.ProductProperties[".properties.hostname"].Value).To(Equal("my-host"))
+		Expect(config.ProductProperties[".properties.new-property"].Value).To(Equal("SAMPLE_STRING_VALUE"))
+		Expect(config.ProductProperties[".properties.flavor"].Value).To(Equal("vanilla"))
+		Expect(config.ProductProperties[".properties.continent"].Value).To(Equal("Australia"))
+		Expect(config.ProductProperties[".properties.continent.australia.required-string"].Value).To(Equal("Sydney"))
+		Expect(config.ProductProperties[".properties.continent.australia.new-string"].Value).To(Equal("SAMPLE_STRING_VALUE"))
+
+		Expect(config.ProductProperties).ToNot(HaveKey(".properties.removed-property"))
+		Expect(config.ProductProperties).ToNot(HaveKey(".properties.now-locked"))
+		Expect(config.ProductProperties).ToNot(HaveKey(".properties.continent.north-america.required-string"))
+	})
+
+	It("keeps the other sections of the base config file", func() {
+		config, err := cmd.MakeConfig()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(config.NetworkProperties.Network.Name).To(Equal("my-network"))
+		Expect(config.ResourceConfig).To(HaveLen(2))
+		Expect(config.ResourceConfig["server"].Instances).To(BeEquivalentTo(5))
+		Expect(config.ResourceConfig["server"].InstanceType.ID).To(Equal("large"))
+		Expect(config.ResourceConfig["new-job"].Instances).To(BeEquivalentTo(2))
+	})
+
+	Context("values are given on the command line", func() {
+		BeforeEach(func() {
+			cmd.Values = map[string]string{
+				".properties.hostname":  "other-host",
+				".properties.continent": "North America",
+			}
+		})
+
+		It("uses those values instead of the ones in the base config file", func() {
+			config, err := cmd.MakeConfig()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(config.ProductProperties[".properties.hostname"].Value).To(Equal("other-host"))
+			Expect(config.ProductProperties[".properties.continent"].Value).To(Equal("North America"))
+			Expect(config.ProductProperties[".properties.continent.north-america.required-string"].Value).To(Equal("Toronto"))
+			Expect(config.ProductProperties).ToNot(HaveKey(".properties.continent.australia.required-string"))
+		})
+	})
+
+	Context("base config file does not exist", func() {
+		BeforeEach(func() {
+			cmd.Base = "/this/path/does/not/exist.yml"
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.MakeConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to read the config file: /this/path/does/not/exist.yml: open /this/path/does/not/exist.yml: no such file or directory"))
+		})
+	})
+})
@@ -0,0 +1,405 @@
+package makeconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/pkg/errors"
+)
+
+type prompter struct {
+	in     *bufio.Reader
+	out    io.Writer
+	inputs map[string]tileinspect.PropertyInput
+	order  map[string]int
+}
+
+type promptedProperty struct {
+	key      string
+	property tileinspect.TileProperty
+}
+
+func collectFormOrder(inputs []tileinspect.PropertyInput, order map[string]int) {
+	for _, input := range inputs {
+		if _, ok := order[input.Reference]; !ok {
+			order[input.Reference] = len(order)
+		}
+		collectFormOrder(input.PropertyInputs, order)
+		collectFormOrder(input.SelectorPropertyInputs, order)
+	}
+}
+
+// inFormOrder sorts properties in the order they appear on the tile's forms, properties without a form come last
+func (p *prompter) inFormOrder(propertyPrefix string, blueprints []tileinspect.TileProperty) []promptedProperty {
+	var properties []promptedProperty
+	for _, property := range blueprints {
+		properties = append(properties, promptedProperty{key: propertyPrefix + "." + property.Name, property: property})
+	}
+
+	position := func(key string) int {
+		if index, ok := p.order[key]; ok {
+			return index
+		}
+		return len(p.order)
+	}
+	sort.SliceStable(properties, func(i, j int) bool {
+		return position(properties[i].key) < position(properties[j].key)
+	})
+	return properties
+}
+
+func (p *prompter) readLine(propertyKey string) (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", errors.Errorf("unexpected end of input while asking for property (%s)", propertyKey)
+		}
+		return "", errors.Wrapf(err, "failed to read the value for property (%s)", propertyKey)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readValue reads a single line, or a whole PEM block when the line starts one
+func (p *prompter) readValue(propertyKey string) (string, error) {
+	line, err := p.readLine(propertyKey)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(strings.TrimSpace(line), "-----BEGIN") {
+		return strings.TrimSpace(line), nil
+	}
+
+	lines := []string{strings.TrimSpace(line)}
+	for !strings.HasPrefix(lines[len(lines)-1], "-----END") {
+		line, err = p.readLine(propertyKey)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+func (p *prompter) describe(propertyKey string, property tileinspect.TileProperty) {
+	input := p.inputs[propertyKey]
+	label := input.Label
+	if label == "" {
+		label = property.Name
+	}
+
+	_, _ = fmt.Fprintf(p.out, "\n%s (%s, %s)\n", label, propertyKey, property.Type)
+	if description := strings.TrimSpace(input.Description); description != "" {
+		for _, line := range strings.Split(description, "\n") {
+			_, _ = fmt.Fprintf(p.out, "  %s\n", strings.TrimSpace(line))
+		}
+	}
+}
+
+// ValidateConstraints checks a value against the min, max and must_match_regex constraints of the property
+func ValidateConstraints(property tileinspect.TileProperty, value interface{}) error {
+	constraints := property.Constraints
+	if constraints == nil {
+		return nil
+	}
+
+	if number, ok := value.(int); ok {
+		if constraints.Min != nil && float64(number) < *constraints.Min {
+			return errors.Errorf("%d is less than the minimum of %v", number, *constraints.Min)
+		}
+		if constraints.Max != nil && float64(number) > *constraints.Max {
+			return errors.Errorf("%d is more than the maximum of %v", number, *constraints.Max)
+		}
+	}
+
+	if text, ok := value.(string); ok {
+		if constraints.MinLength != nil && len(text) < *constraints.MinLength {
+			return errors.Errorf("%q is shorter than the minimum length of %d", text, *constraints.MinLength)
+		}
+		if constraints.MaxLength != nil && len(text) > *constraints.MaxLength {
+			return errors.Errorf("%q is longer than the maximum length of %d", text, *constraints.MaxLength)
+		}
+		for _, pattern := range constraints.MustMatchRegex {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return errors.Wrapf(err, "could not parse the regular expression %q", pattern)
+			}
+			if !re.MatchString(text) {
+				return errors.Errorf("%q does not match %s", text, pattern)
+			}
+		}
+	}
+
+	return nil
+}
+
+var domainName = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+func isIPRange(entry string) bool {
+	if net.ParseIP(entry) != nil {
+		return true
+	}
+	if _, _, err := net.ParseCIDR(entry); err == nil {
+		return true
+	}
+	bounds := strings.Split(entry, "-")
+	return len(bounds) == 2 && net.ParseIP(strings.TrimSpace(bounds[0])) != nil && net.ParseIP(strings.TrimSpace(bounds[1])) != nil
+}
+
+// ValidateFormat checks that a value has the format of its property type, e.g. an IP address for network_address
+func ValidateFormat(property tileinspect.TileProperty, value interface{}) error {
+	text, ok := value.(string)
+	if !ok {
+		return nil
+	}
+
+	switch property.Type {
+	case "network_address":
+		if net.ParseIP(text) == nil {
+			return errors.Errorf("%q is not a valid IP address", text)
+		}
+	case "network_address_list":
+		for _, entry := range strings.Split(text, ",") {
+			if net.ParseIP(strings.TrimSpace(entry)) == nil {
+				return errors.Errorf("%q is not a valid IP address", strings.TrimSpace(entry))
+			}
+		}
+	case "ip_ranges":
+		for _, entry := range strings.Split(text, ",") {
+			if !isIPRange(strings.TrimSpace(entry)) {
+				return errors.Errorf("%q is not a valid IP range, expected an IP address, a CIDR or a range like 10.0.0.1-10.0.0.10", strings.TrimSpace(entry))
+			}
+		}
+	case "email":
+		address, err := mail.ParseAddress(text)
+		if err != nil || address.Address != text {
+			return errors.Errorf("%q is not a valid email address", text)
+		}
+	case "domain":
+		if !domainName.MatchString(text) {
+			return errors.Errorf("%q is not a valid domain", text)
+		}
+	case "wildcard_domain":
+		if !domainName.MatchString(strings.TrimPrefix(text, "*.")) {
+			return errors.Errorf("%q is not a valid domain", text)
+		}
+	case "http_url":
+		parsed, err := url.Parse(text)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.Errorf("%q is not a valid http or https URL", text)
+		}
+	}
+	return nil
+}
+
+func (p *prompter) askChoice(propertyKey string, choices []string, defaultChoice int) (int, error) {
+	_, _ = fmt.Fprintln(p.out, "  Options:")
+	for i, choice := range choices {
+		_, _ = fmt.Fprintf(p.out, "    %d) %s\n", i+1, choice)
+	}
+
+	for {
+		_, _ = fmt.Fprintf(p.out, "Choose an option [%d]: ", defaultChoice+1)
+
+		answer, err := p.readValue(propertyKey)
+		if err != nil {
+			return 0, err
+		}
+
+		if answer == "" {
+			return defaultChoice, nil
+		}
+		if number, err := strconv.Atoi(answer); err == nil && number >= 1 && number <= len(choices) {
+			return number - 1, nil
+		}
+		for i, choice := range choices {
+			if answer == choice {
+				return i, nil
+			}
+		}
+		_, _ = fmt.Fprintf(p.out, "%q is not one of the options\n", answer)
+	}
+}
+
+func (p *prompter) askCredential(propertyKey string, property tileinspect.TileProperty) (interface{}, error) {
+	credential := make(map[string]interface{})
+	for _, field := range CredentialFields[property.Type] {
+		for credential[field] == nil {
+			_, _ = fmt.Fprintf(p.out, "%s: ", field)
+			answer, err := p.readValue(propertyKey)
+			if err != nil {
+				return nil, err
+			}
+			if answer != "" {
+				credential[field] = answer
+			}
+		}
+	}
+	return credential, nil
+}
+
+func (p *prompter) askValue(propertyKey string, property tileinspect.TileProperty) (interface{}, error) {
+	if _, ok := CredentialFields[property.Type]; ok {
+		return p.askCredential(propertyKey, property)
+	}
+
+	for {
+		if property.Default != nil {
+			_, _ = fmt.Fprintf(p.out, "Value [%v]: ", property.Default)
+		} else {
+			_, _ = fmt.Fprint(p.out, "Value: ")
+		}
+
+		answer, err := p.readValue(propertyKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if answer == "" {
+			if property.Default != nil {
+				return nil, nil
+			}
+			_, _ = fmt.Fprintln(p.out, "A value is required")
+			continue
+		}
+
+		value, err := CoerceValue(property, answer)
+		if err == nil {
+			err = ValidateFormat(property, value)
+		}
+		if err == nil {
+			err = ValidateConstraints(property, value)
+		}
+		if err != nil {
+			_, _ = fmt.Fprintln(p.out, err.Error())
+			continue
+		}
+		return value, nil
+	}
+}
+
+func (cmd *Config) askForProperties(p *prompter, propertyPrefix string, blueprints []tileinspect.TileProperty) error {
+	for _, prompted := range p.inFormOrder(propertyPrefix, blueprints) {
+		propertyKey, property := prompted.key, prompted.property
+		if !property.Configurable {
+			continue
+		}
+
+		_, isOverridden := cmd.valueOverrides[propertyKey]
+		if property.Type == "selector" {
+			if !isOverridden {
+				var choices []string
+				defaultChoice := 0
+				for i, option := range property.ChildProperties {
+					choices = append(choices, option.SelectValue)
+					if property.Default == option.SelectValue {
+						defaultChoice = i
+					}
+				}
+
+				p.describe(propertyKey, property)
+				choice, err := p.askChoice(propertyKey, choices, defaultChoice)
+				if err != nil {
+					return err
+				}
+				cmd.answers[propertyKey] = choices[choice]
+				cmd.valueOverrides[propertyKey] = choices[choice]
+			}
+
+			value, _, err := cmd.getValueForProperty(property, cmd.valueOverrides[propertyKey])
+			if err != nil {
+				return errors.Wrapf(err, "invalid value for property (%s)", propertyKey)
+			}
+			for _, option := range property.ChildProperties {
+				if value == option.SelectValue {
+					err = cmd.askForProperties(p, propertyKey+"."+option.Name, option.PropertyBlueprints)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+
+		if isOverridden || property.Optional {
+			continue
+		}
+
+		p.describe(propertyKey, property)
+		if property.Type == "dropdown_select" && len(property.Options) > 0 {
+			var choices []string
+			defaultChoice := 0
+			for i, option := range property.Options {
+				choices = append(choices, fmt.Sprint(option.Name))
+				if property.Default != nil && fmt.Sprint(property.Default) == fmt.Sprint(option.Name) {
+					defaultChoice = i
+				}
+			}
+
+			choice, err := p.askChoice(propertyKey, choices, defaultChoice)
+			if err != nil {
+				return err
+			}
+			cmd.answers[propertyKey] = property.Options[choice].Name
+			continue
+		}
+
+		value, err := p.askValue(propertyKey, property)
+		if err != nil {
+			return err
+		}
+		if value != nil {
+			cmd.answers[propertyKey] = value
+		}
+	}
+
+	return nil
+}
+
+// MakeConfigInteractively asks for the value of each required property before making the config file
+func (cmd *Config) MakeConfigInteractively(in io.Reader, out io.Writer) (*tileinspect.ConfigFile, error) {
+	cmd.answers = nil
+	_, err := cmd.loadValueOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	tileProperties := &tileinspect.TileProperties{}
+	err = cmd.MetadataCmd.LoadMetadata(tileProperties)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load metadata from the tile")
+	}
+
+	p := &prompter{
+		in:     bufio.NewReader(in),
+		out:    out,
+		inputs: tileProperties.PropertyInputs(),
+		order:  make(map[string]int),
+	}
+	for _, formType := range tileProperties.FormTypes {
+		collectFormOrder(formType.PropertyInputs, p.order)
+	}
+
+	cmd.answers = make(map[string]interface{})
+	err = cmd.askForProperties(p, ".properties", tileProperties.PropertyBlueprints)
+	if err != nil {
+		return nil, err
+	}
+	for _, jobType := range tileProperties.JobTypes {
+		err = cmd.askForProperties(p, "."+jobType.Name, jobType.PropertyBlueprints)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cmd.MakeConfig()
+}
@@ -0,0 +1,204 @@
+package makeconfig_test
+
+import (
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/makeconfig"
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("MakeConfigInteractively", func() {
+	var (
+		buffer      *Buffer
+		cmd         *makeconfig.Config
+		metadataCmd *tileinspectfakes.FakeMetadataCmd
+	)
+
+	BeforeEach(func() {
+		buffer = NewBuffer()
+		metadataCmd = &tileinspectfakes.FakeMetadataCmd{}
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			name: product
+			property_blueprints:
+			  - name: max_conns
+			    type: integer
+			    configurable: true
+			    constraints:
+			      min: 1
+			      max: 100
+			  - name: org_name
+			    type: string
+			    configurable: true
+			    constraints:
+			      - must_match_regex: '^[a-z]+$'
+			  - name: optional-string
+			    type: string
+			    configurable: true
+			    optional: true
+			  - name: flavor
+			    type: dropdown_select
+			    configurable: true
+			    default: chocolate
+			    options:
+			      - name: vanilla
+			      - name: chocolate
+			  - name: continent
+			    type: selector
+			    configurable: true
+			    option_templates:
+			      - name: north-america
+			        select_value: North America
+			        property_blueprints:
+			          - name: city
+			            type: string
+			            configurable: true
+			      - name: australia
+			        select_value: Australia
+			        property_blueprints:
+			          - name: admin
+			            type: simple_credentials
+			            configurable: true
+			          - name: region
+			            type: string
+			            configurable: true
+			            default: west
+			form_types:
+			  - name: config
+			    property_inputs:
+			      - reference: .properties.continent
+			        label: Continent
+			        description: Where the servers are
+			      - reference: .properties.org_name
+			        label: Organization
+            `)), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+
+		cmd = &makeconfig.Config{
+			MetadataCmd: metadataCmd,
+		}
+	})
+
+	AfterEach(func() {
+		err := buffer.Close()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("asks for the required properties in form order and makes the config", func() {
+		input := strings.NewReader(heredoc.Doc(`
+			2
+			admin
+			pa55word
+
+			My Org
+			myorg
+			1000
+			10
+
+			`))
+
+		config, err := cmd.MakeConfigInteractively(input, buffer)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(config.ProductProperties[".properties.continent"].Value).To(Equal("Australia"))
+		Expect(config.ProductProperties[".properties.continent.australia.admin"].Value).To(Equal(map[string]interface{}{"identity": "admin", "password": "pa55word"}))
+		Expect(config.ProductProperties[".properties.continent.australia.region"].Value).To(Equal("west"))
+		Expect(config.ProductProperties[".properties.org_name"].Value).To(Equal("myorg"))
+		Expect(config.ProductProperties[".properties.max_conns"].Value).To(Equal(10))
+		Expect(config.ProductProperties[".properties.flavor"].Value).To(Equal("chocolate"))
+		Expect(config.ProductProperties[".properties.optional-string"].Value).To(Equal("SAMPLE_STRING_VALUE"))
+
+		output := string(buffer.Contents())
+		Expect(output).To(ContainSubstring("Continent (.properties.continent, selector)\n  Where the servers are\n  Options:\n    1) North America\n    2) Australia\n"))
+		Expect(output).To(ContainSubstring(`"My Org" does not match ^[a-z]+$`))
+		Expect(output).To(ContainSubstring("1000 is more than the maximum of 100"))
+		Expect(output).ToNot(ContainSubstring("optional-string"))
+		Expect(strings.Index(output, "Organization")).To(BeNumerically("<", strings.Index(output, "max_conns")))
+	})
+
+	Context("a property is given a value", func() {
+		BeforeEach(func() {
+			cmd.Values = map[string]string{
+				".properties.continent": "North America",
+				".properties.max_conns": "5",
+			}
+		})
+
+		It("does not ask for it", func() {
+			input := strings.NewReader("Toronto\nmyorg\n\n")
+
+			config, err := cmd.MakeConfigInteractively(input, buffer)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.ProductProperties[".properties.continent.north-america.city"].Value).To(Equal("Toronto"))
+			Expect(config.ProductProperties[".properties.max_conns"].Value).To(Equal(5))
+			Expect(string(buffer.Contents())).ToNot(ContainSubstring("Continent"))
+		})
+	})
+
+	Context("the input ends early", func() {
+		It("returns an error", func() {
+			_, err := cmd.MakeConfigInteractively(strings.NewReader("1\n"), buffer)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("unexpected end of input while asking for property (.properties.continent.north-america.city)"))
+		})
+	})
+})
+
+var _ = Describe("ValidateConstraints", func() {
+	It("checks the length of strings", func() {
+		minLength := 3
+		property := tileinspect.TileProperty{
+			Constraints: &tileinspect.PropertyConstraints{MinLength: &minLength},
+		}
+		Expect(makeconfig.ValidateConstraints(property, "abc")).To(Succeed())
+		err := makeconfig.ValidateConstraints(property, "ab")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`"ab" is shorter than the minimum length of 3`))
+	})
+})
+
+var _ = Describe("ValidateFormat", func() {
+	DescribeTable("checks the format of the property type",
+		func(propertyType string, value string, expectedError string) {
+			err := makeconfig.ValidateFormat(tileinspect.TileProperty{Type: propertyType}, value)
+			if expectedError == "" {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(expectedError))
+			}
+		},
+		Entry("an IP address", "network_address", "10.0.0.1", ""),
+		Entry("an invalid IP address", "network_address", "10.0.0", `"10.0.0" is not a valid IP address`),
+		Entry("a list of IP addresses", "network_address_list", "10.0.0.1, 10.0.0.2", ""),
+		Entry("an invalid list of IP addresses", "network_address_list", "10.0.0.1,bad", `"bad" is not a valid IP address`),
+		Entry("IP ranges", "ip_ranges", "10.0.0.1,10.0.1.0/24,10.0.2.1-10.0.2.10", ""),
+		Entry("an invalid IP range", "ip_ranges", "10.0.0.1-", `"10.0.0.1-" is not a valid IP range, expected an IP address, a CIDR or a range like 10.0.0.1-10.0.0.10`),
+		Entry("an email address", "email", "admin@example.com", ""),
+		Entry("an invalid email address", "email", "Admin <admin@example.com>", `"Admin <admin@example.com>" is not a valid email address`),
+		Entry("a domain", "domain", "sys.example.com", ""),
+		Entry("an invalid domain", "domain", "*.example.com", `"*.example.com" is not a valid domain`),
+		Entry("a wildcard domain", "wildcard_domain", "*.example.com", ""),
+		Entry("a URL", "http_url", "https://example.com/path", ""),
+		Entry("an invalid URL", "http_url", "ftp://example.com", `"ftp://example.com" is not a valid http or https URL`),
+		Entry("a string", "string", "anything", ""),
+	)
+})
+
+var _ = Describe("Execute", func() {
+	It("does not ask for values for a matrix", func() {
+		cmd := &makeconfig.Config{Interactive: true, Matrix: true, OutputDir: "configs"}
+		err := cmd.Execute(nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("--interactive cannot be used with --matrix"))
+	})
+})
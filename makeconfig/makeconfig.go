@@ -2,8 +2,10 @@ package makeconfig
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
-	"strings"
+	"strconv"
 
 	"github.com/cf-platform-eng/tileinspect"
 	"github.com/cf-platform-eng/tileinspect/checkconfig"
@@ -16,9 +18,28 @@ type Config struct {
 	tileinspect.TileConfig
 	//duplicate choice required by go-flags
 	// nolint:staticcheck
-	Format      string            `long:"format" short:"f" description:"output file type" choice:"yaml" choice:"json" default:"yaml"`
-	Values      map[string]string `long:"value" short:"v" description:"set a value for a given property with the format: .properties.key:value"`
-	MetadataCmd tileinspect.MetadataCmd
+	Format          string            `long:"format" short:"f" description:"output file type" choice:"yaml" choice:"json" default:"yaml"`
+	Values          map[string]string `long:"value" short:"v" description:"set a value for a given property with the format: .properties.key:value"`
+	ValuesFile      string            `long:"values-file" description:"path to a YAML or JSON file with values for properties, overridden by --value"`
+	Base            string            `long:"base" description:"path to an existing config file whose valid values are kept, only filling in missing properties"`
+	Annotate        bool              `long:"annotate" description:"add comments with the label, description, type and options of each property (yaml only)"`
+	Matrix          bool              `long:"matrix" description:"write a config file for each combination of selector options, requires --output-dir"`
+	OutputDir       string            `long:"output-dir" description:"directory for the config files written with --matrix"`
+	MatrixSelectors []string          `long:"matrix-selector" description:"only enumerate the options of this selector with --matrix (can be repeated)"`
+	MaxConfigs      int               `long:"max-configs" description:"maximum number of config files to write with --matrix"`
+	Realistic       bool              `long:"realistic" description:"use sample values that satisfy the type and constraints of each property instead of placeholders"`
+	Seed            int64             `long:"seed" description:"seed for the values generated with --realistic" default:"0"`
+	SecretsAsVars   bool              `long:"secrets-as-vars" description:"write secret and credential properties as ((variable)) placeholders, requires --vars-file"`
+	VarsFile        string            `long:"vars-file" description:"path to write the template of the variables used with --secrets-as-vars"`
+	Interactive     bool              `long:"interactive" description:"ask for the value of each required property"`
+	MetadataCmd     tileinspect.MetadataCmd
+
+	valueOverrides map[string]interface{}
+	valueSources   map[string]ValueSource
+	tileProperties *tileinspect.TileProperties
+	generator      *sampleGenerator
+	variables      map[string]interface{}
+	answers        map[string]interface{}
 }
 
 var SampleValues = map[string]interface{}{
@@ -34,31 +55,62 @@ var SampleValues = map[string]interface{}{
 	"vm_type_dropdown": "{vm_type}",
 }
 
-func (cmd *Config) getValueForProperty(property tileinspect.TileProperty, valueOverride string) interface{} {
-	if valueOverride != "" {
-		property.Default = valueOverride
+const (
+	Automatic                        = "automatic"
+	SampleNetworkName                = "SAMPLE_NETWORK_NAME"
+	SampleServiceNetworkName         = "SAMPLE_SERVICE_NETWORK_NAME"
+	SampleAvailabilityZoneName       = "SAMPLE_AVAILABILITY_ZONE_NAME"
+	DefaultErrandState               = "default"
+	persistentDiskResourceDefinition = "persistent_disk"
+)
+
+// ValueSource describes where the value of a property in the generated config file came from
+type ValueSource string
+
+const (
+	SourceGiven       ValueSource = "given value"
+	SourceBase        ValueSource = "base config file"
+	SourceDefault     ValueSource = "tile default"
+	SourceFirstOption ValueSource = "first option"
+	SourceSample      ValueSource = "sample value, replace before use"
+	SourceGenerated   ValueSource = "generated value"
+	SourceMatrix      ValueSource = "matrix choice"
+)
+
+func (cmd *Config) getValueForProperty(property tileinspect.TileProperty, valueOverride interface{}) (interface{}, ValueSource, error) {
+	if valueOverride != nil && valueOverride != "" {
+		value, err := CoerceTypedValue(property, valueOverride)
+		return value, SourceGiven, err
 	}
 
 	if property.Default != nil {
 		if property.Type == "secret" {
-			return map[string]interface{}{
-				"secret": property.Default.(string),
+			if secret, ok := property.Default.(map[string]interface{}); ok {
+				return secret, SourceDefault, nil
 			}
+			return map[string]interface{}{
+				"secret": fmt.Sprint(property.Default),
+			}, SourceDefault, nil
 		} else {
-			return property.Default
+			return property.Default, SourceDefault, nil
 		}
 	}
 
 	if property.Type == "dropdown_select" {
-		return property.Options[0].Name
+		return property.Options[0].Name, SourceFirstOption, nil
 	} else if property.Type == "selector" {
-		return property.ChildProperties[0].SelectValue
+		return property.ChildProperties[0].SelectValue, SourceFirstOption, nil
 	}
 
-	return SampleValues[property.Type]
+	if cmd.generator != nil {
+		value, err := cmd.generator.valueFor(property)
+		return value, SourceGenerated, err
+	}
+
+	return SampleValues[property.Type], SourceSample, nil
 }
 
-func (cmd *Config) setValuesForProperties(config *tileinspect.ConfigFile, propertyPrefix string, tileProperties []tileinspect.TileProperty) {
+func (cmd *Config) setValuesForProperties(config *tileinspect.ConfigFile, propertyPrefix string, tileProperties []tileinspect.TileProperty) error {
 	for _, property := range tileProperties {
 		propertyKey := propertyPrefix + "." + property.Name
 		if !property.Configurable {
@@ -66,49 +118,263 @@ func (cmd *Config) setValuesForProperties(config *tileinspect.ConfigFile, proper
 		}
 
 		if config.ProductProperties[propertyKey] == nil {
+			value, source, err := cmd.getValueForProperty(property, cmd.valueOverrides[propertyKey])
+			if err != nil {
+				return errors.Wrapf(err, "invalid value for property (%s)", propertyKey)
+			}
+
 			config.ProductProperties[propertyKey] = &tileinspect.ConfigFileProperty{
-				Value: cmd.getValueForProperty(property, cmd.Values[propertyKey]),
+				Value: value,
 				Type:  property.Type,
 			}
+			cmd.valueSources[propertyKey] = source
+		} else if _, ok := cmd.valueSources[propertyKey]; !ok {
+			cmd.valueSources[propertyKey] = SourceBase
 		}
 
 		if property.Type == "selector" {
 			for _, option := range property.ChildProperties {
 				if config.ProductProperties[propertyKey].Value == option.SelectValue {
-					cmd.setValuesForProperties(config, propertyKey+"."+option.Name, option.PropertyBlueprints)
+					err := cmd.setValuesForProperties(config, propertyKey+"."+option.Name, option.PropertyBlueprints)
+					if err != nil {
+						return err
+					}
 				}
 			}
 		}
 	}
+
+	return nil
+}
+
+func (cmd *Config) setValuesForTile(config *tileinspect.ConfigFile, tileProperties *tileinspect.TileProperties) error {
+	err := cmd.setValuesForProperties(config, ".properties", tileProperties.PropertyBlueprints)
+	if err != nil {
+		return err
+	}
+
+	for _, jobType := range tileProperties.JobTypes {
+		err = cmd.setValuesForProperties(config, "."+jobType.Name, jobType.PropertyBlueprints)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func makeNetworkProperties(tileProperties *tileinspect.TileProperties) *tileinspect.NetworkProperties {
+	networkProperties := &tileinspect.NetworkProperties{
+		Network: tileinspect.NamedReference{Name: SampleNetworkName},
+		OtherAvailabilityZones: []tileinspect.NamedReference{
+			{Name: SampleAvailabilityZoneName},
+		},
+		SingletonAvailabilityZone: tileinspect.NamedReference{Name: SampleAvailabilityZoneName},
+	}
+
+	if tileProperties.ServiceBroker {
+		networkProperties.ServiceNetwork = &tileinspect.NamedReference{Name: SampleServiceNetworkName}
+	}
+
+	return networkProperties
+}
+
+func formatDiskSize(size interface{}) string {
+	if value, ok := size.(float64); ok {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return fmt.Sprint(size)
+}
+
+func makeResourceConfig(jobTypes []tileinspect.JobType) map[string]*tileinspect.ResourceConfig {
+	if len(jobTypes) == 0 {
+		return nil
+	}
+
+	resourceConfig := make(map[string]*tileinspect.ResourceConfig)
+	for _, jobType := range jobTypes {
+		resource := &tileinspect.ResourceConfig{
+			Instances:    Automatic,
+			InstanceType: tileinspect.InstanceType{ID: Automatic},
+		}
+
+		instanceDefinition := jobType.InstanceDefinition
+		if instanceDefinition != nil && instanceDefinition.Configurable && instanceDefinition.Default != nil {
+			resource.Instances = instanceDefinition.Default
+		}
+
+		for _, resourceDefinition := range jobType.ResourceDefinitions {
+			if resourceDefinition.Name != persistentDiskResourceDefinition {
+				continue
+			}
+
+			resource.PersistentDisk = &tileinspect.PersistentDiskSize{SizeMB: Automatic}
+			if resourceDefinition.Configurable && resourceDefinition.Default != nil {
+				resource.PersistentDisk.SizeMB = formatDiskSize(resourceDefinition.Default)
+			}
+		}
+
+		resourceConfig[jobType.Name] = resource
+	}
+
+	return resourceConfig
+}
+
+func getErrandState(errand tileinspect.Errand) interface{} {
+	switch runDefault := errand.RunDefault.(type) {
+	case bool:
+		return runDefault
+	case string:
+		if runDefault == "on" {
+			return true
+		} else if runDefault == "off" {
+			return false
+		}
+		return runDefault
+	}
+	return DefaultErrandState
+}
+
+func makeErrandConfig(tileProperties *tileinspect.TileProperties) map[string]*tileinspect.ErrandConfig {
+	if len(tileProperties.PostDeployErrands) == 0 && len(tileProperties.PreDeleteErrands) == 0 {
+		return nil
+	}
+
+	errandConfig := make(map[string]*tileinspect.ErrandConfig)
+	getErrand := func(name string) *tileinspect.ErrandConfig {
+		if errandConfig[name] == nil {
+			errandConfig[name] = &tileinspect.ErrandConfig{}
+		}
+		return errandConfig[name]
+	}
+
+	for _, errand := range tileProperties.PostDeployErrands {
+		getErrand(errand.Name).PostDeployState = getErrandState(errand)
+	}
+	for _, errand := range tileProperties.PreDeleteErrands {
+		getErrand(errand.Name).PreDeleteState = getErrandState(errand)
+	}
+
+	return errandConfig
+}
+
+func (cmd *Config) loadValueOverrides() (*ValuesFile, error) {
+	cmd.valueOverrides = make(map[string]interface{})
+	cmd.valueSources = make(map[string]ValueSource)
+	cmd.generator = nil
+	cmd.variables = nil
+	if cmd.Realistic {
+		cmd.generator = newSampleGenerator(cmd.Seed)
+	}
+
+	var valuesFile *ValuesFile
+	if cmd.ValuesFile != "" {
+		var err error
+		valuesFile, err = LoadValuesFile(cmd.ValuesFile)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, value := range valuesFile.Values {
+			cmd.valueOverrides[key] = value
+		}
+	}
+
+	for key, value := range cmd.Values {
+		cmd.valueOverrides[key] = value
+	}
+
+	for key, value := range cmd.answers {
+		cmd.valueOverrides[key] = value
+	}
+
+	return valuesFile, nil
 }
 
 func (cmd *Config) MakeConfig() (*tileinspect.ConfigFile, error) {
+	valuesFile, err := cmd.loadValueOverrides()
+	if err != nil {
+		return nil, err
+	}
+
 	tileProperties := &tileinspect.TileProperties{}
-	err := cmd.MetadataCmd.LoadMetadata(tileProperties)
+	err = cmd.MetadataCmd.LoadMetadata(tileProperties)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to load metadata from the tile")
 	}
+	cmd.tileProperties = tileProperties
 
 	config := &tileinspect.ConfigFile{
 		ProductName:       tileProperties.Name,
 		ProductProperties: make(map[string]*tileinspect.ConfigFileProperty),
 	}
 
-	cmd.setValuesForProperties(config, ".properties", tileProperties.PropertyBlueprints)
+	var base *tileinspect.ConfigFile
+	if cmd.Base != "" {
+		base, err = cmd.loadBaseConfig(tileProperties)
+		if err != nil {
+			return nil, err
+		}
+		config.ProductProperties = base.ProductProperties
+	}
+
+	err = cmd.setValuesForTile(config, tileProperties)
+	if err != nil {
+		return nil, err
+	}
+	config.NetworkProperties = makeNetworkProperties(tileProperties)
+	config.ResourceConfig = makeResourceConfig(tileProperties.JobTypes)
+	config.ErrandConfig = makeErrandConfig(tileProperties)
+	if base != nil {
+		applyBaseSections(config, base)
+	}
+	if valuesFile != nil {
+		valuesFile.applySections(config)
+	}
+
+	if cmd.SecretsAsVars {
+		cmd.variables = ReplaceSecretsWithVariables(config, tileProperties)
+	}
 
 	check := &checkconfig.Config{}
 	errs := check.CompareProperties(config, tileProperties)
 	if len(errs) > 0 {
-		errorStrings := make([]string, len(errs))
-		for i := range errs {
-			errorStrings[i] = errs[i].Error()
-		}
-		return nil, errors.Errorf("failed to construct a valid config file:\n%s\n", strings.Join(errorStrings, "\n"))
+		return nil, errors.Errorf("failed to construct a valid config file:\n%s\n", checkconfig.JoinErrors(errs))
 	}
 
 	return config, nil
 }
 
+// Variables returns the variables used by the last config file made with --secrets-as-vars
+func (cmd *Config) Variables() map[string]interface{} {
+	return cmd.variables
+}
+
+func (cmd *Config) WriteConfig(out io.Writer, config *tileinspect.ConfigFile) error {
+	var bytes []byte
+	var err error
+	if cmd.Annotate {
+		if cmd.Format != "yaml" {
+			return errors.New("annotations are only supported for the yaml format")
+		}
+		bytes, err = cmd.annotateConfig(config)
+	} else if cmd.Format == "yaml" {
+		bytes, err = yaml.Marshal(config)
+	} else if cmd.Format == "json" {
+		bytes, err = json.Marshal(config)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to convert config file")
+	}
+
+	_, err = out.Write(bytes)
+	if err != nil {
+		return errors.Wrap(err, "failed to print config file")
+	}
+
+	return nil
+}
+
 func (cmd *Config) Execute(args []string) error {
 	cmd.MetadataCmd = &metadata.Config{
 		TileConfig: tileinspect.TileConfig{
@@ -116,25 +382,42 @@ func (cmd *Config) Execute(args []string) error {
 		},
 	}
 
-	config, err := cmd.MakeConfig()
-	if err != nil {
-		return err
+	if cmd.SecretsAsVars && !cmd.Matrix && cmd.VarsFile == "" {
+		return errors.New("--vars-file is required when using --secrets-as-vars")
 	}
 
-	var bytes []byte
-	if cmd.Format == "yaml" {
-		bytes, err = yaml.Marshal(config)
-	} else if cmd.Format == "json" {
-		bytes, err = json.Marshal(config)
+	if cmd.Matrix {
+		if cmd.Interactive {
+			return errors.New("--interactive cannot be used with --matrix")
+		}
+		if cmd.OutputDir == "" {
+			return errors.New("--output-dir is required when using --matrix")
+		}
+
+		entries, err := cmd.MakeConfigMatrix()
+		if err != nil {
+			return err
+		}
+		return cmd.WriteConfigMatrix(cmd.OutputDir, entries)
+	}
+
+	var config *tileinspect.ConfigFile
+	var err error
+	if cmd.Interactive {
+		config, err = cmd.MakeConfigInteractively(os.Stdin, os.Stderr)
+	} else {
+		config, err = cmd.MakeConfig()
 	}
 	if err != nil {
-		return errors.Wrap(err, "failed to convert config file")
+		return err
 	}
 
-	_, err = os.Stdout.Write(bytes)
-	if err != nil {
-		return errors.Wrap(err, "failed to print config file")
+	if cmd.SecretsAsVars {
+		err = cmd.WriteVarsFile(cmd.VarsFile, cmd.variables)
+		if err != nil {
+			return err
+		}
 	}
 
-	return err
+	return cmd.WriteConfig(os.Stdout, config)
 }
@@ -385,4 +385,220 @@ var _ = Describe("MakeConfig", func() {
 			Expect(config.ProductProperties[".properties.browser.explorer.required-string"].Value).To(Equal("SAMPLE_STRING_VALUE"))
 		})
 	})
+
+	Describe("job type properties", func() {
+		BeforeEach(func() {
+			metadataCmd.LoadMetadataStub = func(target interface{}) error {
+				err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			property_blueprints:
+			  - name: basic-property
+			    type: string
+			    configurable: true
+			job_types:
+			  - name: server
+			    property_blueprints:
+			      - name: required-string
+			        type: string
+			        configurable: true
+			      - name: port
+			        type: port
+			        configurable: true
+			        default: 8080
+			      - name: non-configurable-property
+			        type: string
+			        configurable: false
+			      - name: mode
+			        type: selector
+			        configurable: true
+			        option_templates:
+			          - name: simple
+			            select_value: Simple
+			            property_blueprints:
+			              - name: required-string
+			                type: string
+			                configurable: true
+			          - name: advanced
+			            select_value: Advanced
+			            property_blueprints:
+			              - name: required-integer
+			                type: integer
+			                configurable: true
+            `)), &target)
+				Expect(err).ToNot(HaveOccurred())
+				return nil
+			}
+
+			cmd.Values = make(map[string]string)
+			cmd.Values[".server.required-string"] = "overridden"
+			cmd.Values[".server.mode"] = "Advanced"
+		})
+
+		It("returns a config with values for the job type properties", func() {
+			config, err := cmd.MakeConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config).ToNot(BeNil())
+			Expect(config.ProductProperties).To(HaveKey(".properties.basic-property"))
+			Expect(config.ProductProperties).To(HaveKey(".server.required-string"))
+			Expect(config.ProductProperties[".server.required-string"].Value).To(Equal("overridden"))
+			Expect(config.ProductProperties).To(HaveKey(".server.port"))
+			Expect(config.ProductProperties[".server.port"].Value).To(BeEquivalentTo(8080))
+			Expect(config.ProductProperties).ToNot(HaveKey(".server.non-configurable-property"))
+			Expect(config.ProductProperties).To(HaveKey(".server.mode"))
+			Expect(config.ProductProperties[".server.mode"].Value).To(Equal("Advanced"))
+			Expect(config.ProductProperties).To(HaveKey(".server.mode.advanced.required-integer"))
+			Expect(config.ProductProperties[".server.mode.advanced.required-integer"].Value).To(Equal(0))
+			Expect(config.ProductProperties).ToNot(HaveKey(".server.mode.simple.required-string"))
+		})
+	})
+
+	Describe("network properties", func() {
+		BeforeEach(func() {
+			metadataCmd.LoadMetadataStub = func(target interface{}) error {
+				err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			name: product
+			property_blueprints:
+            `)), &target)
+				Expect(err).ToNot(HaveOccurred())
+				return nil
+			}
+		})
+
+		It("returns placeholder network and availability zone names", func() {
+			config, err := cmd.MakeConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.NetworkProperties).ToNot(BeNil())
+			Expect(config.NetworkProperties.Network.Name).To(Equal("SAMPLE_NETWORK_NAME"))
+			Expect(config.NetworkProperties.ServiceNetwork).To(BeNil())
+			Expect(config.NetworkProperties.SingletonAvailabilityZone.Name).To(Equal("SAMPLE_AVAILABILITY_ZONE_NAME"))
+			Expect(config.NetworkProperties.OtherAvailabilityZones).To(HaveLen(1))
+			Expect(config.NetworkProperties.OtherAvailabilityZones[0].Name).To(Equal("SAMPLE_AVAILABILITY_ZONE_NAME"))
+		})
+
+		Context("the tile is a service broker", func() {
+			BeforeEach(func() {
+				metadataCmd.LoadMetadataStub = func(target interface{}) error {
+					err := yaml.Unmarshal([]byte(heredoc.Doc(`
+				---
+				name: product
+				service_broker: true
+                `)), &target)
+					Expect(err).ToNot(HaveOccurred())
+					return nil
+				}
+			})
+
+			It("returns a placeholder service network", func() {
+				config, err := cmd.MakeConfig()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(config.NetworkProperties.ServiceNetwork).ToNot(BeNil())
+				Expect(config.NetworkProperties.ServiceNetwork.Name).To(Equal("SAMPLE_SERVICE_NETWORK_NAME"))
+			})
+		})
+	})
+
+	Describe("resource config", func() {
+		BeforeEach(func() {
+			metadataCmd.LoadMetadataStub = func(target interface{}) error {
+				err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			name: product
+			job_types:
+			  - name: server
+			    instance_definition:
+			      name: instances
+			      type: integer
+			      configurable: true
+			      default: 3
+			    resource_definitions:
+			      - name: ram
+			        type: integer
+			        configurable: true
+			        default: 1024
+			      - name: persistent_disk
+			        type: integer
+			        configurable: true
+			        default: 10240000
+			  - name: worker
+			    instance_definition:
+			      name: instances
+			      type: integer
+			      configurable: false
+			      default: 1
+			    resource_definitions:
+			      - name: persistent_disk
+			        type: integer
+			        configurable: false
+			        default: 2048
+			  - name: proxy
+			    resource_definitions:
+			      - name: ram
+			        type: integer
+			        configurable: true
+			        default: 1024
+            `)), &target)
+				Expect(err).ToNot(HaveOccurred())
+				return nil
+			}
+		})
+
+		It("returns resource config for each job type", func() {
+			config, err := cmd.MakeConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.ResourceConfig).To(HaveLen(3))
+
+			Expect(config.ResourceConfig).To(HaveKey("server"))
+			Expect(config.ResourceConfig["server"].Instances).To(BeEquivalentTo(3))
+			Expect(config.ResourceConfig["server"].InstanceType.ID).To(Equal("automatic"))
+			Expect(config.ResourceConfig["server"].PersistentDisk).ToNot(BeNil())
+			Expect(config.ResourceConfig["server"].PersistentDisk.SizeMB).To(Equal("10240000"))
+
+			Expect(config.ResourceConfig).To(HaveKey("worker"))
+			Expect(config.ResourceConfig["worker"].Instances).To(Equal("automatic"))
+			Expect(config.ResourceConfig["worker"].PersistentDisk).ToNot(BeNil())
+			Expect(config.ResourceConfig["worker"].PersistentDisk.SizeMB).To(Equal("automatic"))
+
+			Expect(config.ResourceConfig).To(HaveKey("proxy"))
+			Expect(config.ResourceConfig["proxy"].Instances).To(Equal("automatic"))
+			Expect(config.ResourceConfig["proxy"].PersistentDisk).To(BeNil())
+		})
+	})
+
+	Describe("errand config", func() {
+		BeforeEach(func() {
+			metadataCmd.LoadMetadataStub = func(target interface{}) error {
+				err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			name: product
+			post_deploy_errands:
+			  - name: smoke-tests
+			  - name: register-broker
+			    run_default: off
+			  - name: upgrade-all
+			    run_default: when-changed
+			pre_delete_errands:
+			  - name: delete-all
+			    run_default: on
+			  - name: register-broker
+            `)), &target)
+				Expect(err).ToNot(HaveOccurred())
+				return nil
+			}
+		})
+
+		It("returns the default state for each errand", func() {
+			config, err := cmd.MakeConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.ErrandConfig).To(HaveLen(4))
+
+			Expect(config.ErrandConfig["smoke-tests"].PostDeployState).To(Equal("default"))
+			Expect(config.ErrandConfig["smoke-tests"].PreDeleteState).To(BeNil())
+			Expect(config.ErrandConfig["register-broker"].PostDeployState).To(Equal(false))
+			Expect(config.ErrandConfig["register-broker"].PreDeleteState).To(Equal("default"))
+			Expect(config.ErrandConfig["upgrade-all"].PostDeployState).To(Equal("when-changed"))
+			Expect(config.ErrandConfig["delete-all"].PostDeployState).To(BeNil())
+			Expect(config.ErrandConfig["delete-all"].PreDeleteState).To(Equal(true))
+		})
+	})
 })
@@ -0,0 +1,237 @@
+package makeconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// Selection is the option chosen for a selector in one of the config files of a matrix
+type Selection struct {
+	Selector string `json:"selector"`
+	Option   string `json:"option"`
+	Value    string `json:"value"`
+}
+
+type MatrixEntry struct {
+	File       string                  `json:"file"`
+	Selections []Selection             `json:"selections"`
+	VarsFile   string                  `json:"vars-file,omitempty"`
+	Config     *tileinspect.ConfigFile `json:"-"`
+	Variables  map[string]interface{}  `json:"-"`
+
+	// cmd made the config file, and has its value sources and tile properties for --annotate
+	cmd *Config
+}
+
+// MatrixIndex is the name of the index of the config files written with --matrix, without extension
+const MatrixIndex = "index"
+
+type propertyGroup struct {
+	prefix     string
+	blueprints []tileinspect.TileProperty
+}
+
+func (cmd *Config) isMatrixSelector(propertyKey string) bool {
+	if _, ok := cmd.valueOverrides[propertyKey]; ok {
+		return false
+	}
+	return len(cmd.MatrixSelectors) == 0 || stringInSlice(propertyKey, cmd.MatrixSelectors)
+}
+
+// enumerateSelections returns the combinations of selector options, stopping at limit combinations when limit is positive
+func (cmd *Config) enumerateSelections(groups []propertyGroup, limit int) ([][]Selection, error) {
+	if len(groups) == 0 {
+		return [][]Selection{{}}, nil
+	}
+
+	group := groups[0]
+	for i, property := range group.blueprints {
+		propertyKey := group.prefix + "." + property.Name
+		if property.Type != "selector" || !property.Configurable || len(property.ChildProperties) == 0 {
+			continue
+		}
+
+		remaining := append([]propertyGroup{{prefix: group.prefix, blueprints: group.blueprints[i+1:]}}, groups[1:]...)
+
+		if !cmd.isMatrixSelector(propertyKey) {
+			// Follow the option that would be chosen anyway, it may contain selectors to enumerate
+			value, _, err := cmd.getValueForProperty(property, cmd.valueOverrides[propertyKey])
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid value for property (%s)", propertyKey)
+			}
+			for _, option := range property.ChildProperties {
+				if value == option.SelectValue {
+					remaining = append([]propertyGroup{{prefix: propertyKey + "." + option.Name, blueprints: option.PropertyBlueprints}}, remaining...)
+				}
+			}
+			return cmd.enumerateSelections(remaining, limit)
+		}
+
+		var combinations [][]Selection
+		for _, option := range property.ChildProperties {
+			if limit > 0 && len(combinations) >= limit {
+				break
+			}
+
+			optionGroups := append([]propertyGroup{{prefix: propertyKey + "." + option.Name, blueprints: option.PropertyBlueprints}}, remaining...)
+			tailLimit := 0
+			if limit > 0 {
+				tailLimit = limit - len(combinations)
+			}
+			tails, err := cmd.enumerateSelections(optionGroups, tailLimit)
+			if err != nil {
+				return nil, err
+			}
+
+			selection := Selection{
+				Selector: propertyKey,
+				Option:   option.Name,
+				Value:    option.SelectValue,
+			}
+			for _, tail := range tails {
+				combinations = append(combinations, append([]Selection{selection}, tail...))
+			}
+		}
+		return combinations, nil
+	}
+
+	return cmd.enumerateSelections(groups[1:], limit)
+}
+
+var unsafeFileNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+func matrixFileName(productName string, selections []Selection, extension string, used map[string]bool) string {
+	parts := []string{productName}
+	for _, selection := range selections {
+		parts = append(parts, selection.Option)
+	}
+	baseName := unsafeFileNameCharacters.ReplaceAllString(strings.Join(parts, "-"), "_")
+
+	fileName := baseName + "." + extension
+	for i := 2; used[fileName]; i++ {
+		fileName = fmt.Sprintf("%s-%d.%s", baseName, i, extension)
+	}
+	used[fileName] = true
+	return fileName
+}
+
+func (cmd *Config) MakeConfigMatrix() ([]*MatrixEntry, error) {
+	_, err := cmd.loadValueOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	tileProperties := &tileinspect.TileProperties{}
+	err = cmd.MetadataCmd.LoadMetadata(tileProperties)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load metadata from the tile")
+	}
+
+	groups := []propertyGroup{{prefix: ".properties", blueprints: tileProperties.PropertyBlueprints}}
+	for _, jobType := range tileProperties.JobTypes {
+		groups = append(groups, propertyGroup{prefix: "." + jobType.Name, blueprints: jobType.PropertyBlueprints})
+	}
+
+	combinations, err := cmd.enumerateSelections(groups, cmd.MaxConfigs)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*MatrixEntry
+	// The index is reserved, so a product named index gets index-2
+	usedFileNames := map[string]bool{MatrixIndex + "." + cmd.Format: true}
+	for _, selections := range combinations {
+		entryCmd := &Config{}
+		*entryCmd = *cmd
+		entryCmd.Values = make(map[string]string)
+		for key, value := range cmd.Values {
+			entryCmd.Values[key] = value
+		}
+		for _, selection := range selections {
+			entryCmd.Values[selection.Selector] = selection.Value
+		}
+
+		config, err := entryCmd.MakeConfig()
+		if err != nil {
+			return nil, err
+		}
+		for _, selection := range selections {
+			entryCmd.valueSources[selection.Selector] = SourceMatrix
+		}
+
+		entry := &MatrixEntry{
+			File:       matrixFileName(tileProperties.Name, selections, cmd.Format, usedFileNames),
+			Selections: selections,
+			Config:     config,
+			cmd:        entryCmd,
+		}
+		if cmd.SecretsAsVars {
+			entry.VarsFile = strings.TrimSuffix(entry.File, "."+cmd.Format) + "-vars.yml"
+			entry.Variables = entryCmd.Variables()
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (cmd *Config) WriteConfigMatrix(outputDir string, entries []*MatrixEntry) error {
+	err := os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the output directory: %s", outputDir)
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(outputDir, entry.File)
+		file, err := os.Create(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the config file: %s", path)
+		}
+
+		entryCmd := entry.cmd
+		if entryCmd == nil {
+			entryCmd = cmd
+		}
+		err = entryCmd.WriteConfig(file, entry.Config)
+		closeErr := file.Close()
+		if err != nil {
+			return err
+		}
+		if closeErr != nil {
+			return errors.Wrapf(closeErr, "failed to write the config file: %s", path)
+		}
+
+		if entry.VarsFile != "" {
+			err = cmd.WriteVarsFile(filepath.Join(outputDir, entry.VarsFile), entry.Variables)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	var index []byte
+	if cmd.Format == "json" {
+		index, err = json.Marshal(entries)
+	} else {
+		index, err = yaml.Marshal(entries)
+	}
+	if err != nil { // !branch-not-tested No good way to force this
+		return errors.Wrap(err, "failed to convert the matrix index")
+	}
+
+	path := filepath.Join(outputDir, MatrixIndex+"."+cmd.Format)
+	err = os.WriteFile(path, index, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write the matrix index: %s", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,275 @@
+package makeconfig_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/makeconfig"
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MakeConfigMatrix", func() {
+	var (
+		cmd         *makeconfig.Config
+		metadataCmd *tileinspectfakes.FakeMetadataCmd
+	)
+
+	BeforeEach(func() {
+		metadataCmd = &tileinspectfakes.FakeMetadataCmd{}
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			name: product
+			property_blueprints:
+			  - name: continent
+			    configurable: true
+			    type: selector
+			    option_templates:
+			      - name: north-america
+			        select_value: North America
+			        property_blueprints:
+			          - name: country
+			            configurable: true
+			            type: selector
+			            option_templates:
+			              - name: canada
+			                select_value: Canada
+			                property_blueprints:
+			                  - name: province
+			                    configurable: true
+			                    type: string
+			              - name: mexico
+			                select_value: Mexico
+			                property_blueprints: []
+			      - name: australia
+			        select_value: Australia
+			        property_blueprints:
+			          - name: city
+			            configurable: true
+			            type: string
+			  - name: browser
+			    configurable: true
+			    type: selector
+			    default: Google Chrome
+			    option_templates:
+			      - name: explorer
+			        select_value: Internet Explorer
+			        property_blueprints: []
+			      - name: chrome
+			        select_value: Google Chrome
+			        property_blueprints: []
+            `)), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+
+		cmd = &makeconfig.Config{
+			Format:      "yaml",
+			MetadataCmd: metadataCmd,
+		}
+	})
+
+	It("makes a config for every combination of selector options", func() {
+		entries, err := cmd.MakeConfigMatrix()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(6))
+
+		var files []string
+		for _, entry := range entries {
+			files = append(files, entry.File)
+		}
+		Expect(files).To(Equal([]string{
+			"product-north-america-canada-explorer.yaml",
+			"product-north-america-canada-chrome.yaml",
+			"product-north-america-mexico-explorer.yaml",
+			"product-north-america-mexico-chrome.yaml",
+			"product-australia-explorer.yaml",
+			"product-australia-chrome.yaml",
+		}))
+
+		Expect(entries[0].Selections).To(Equal([]makeconfig.Selection{
+			{Selector: ".properties.continent", Option: "north-america", Value: "North America"},
+			{Selector: ".properties.continent.north-america.country", Option: "canada", Value: "Canada"},
+			{Selector: ".properties.browser", Option: "explorer", Value: "Internet Explorer"},
+		}))
+		Expect(entries[0].Config.ProductProperties[".properties.continent"].Value).To(Equal("North America"))
+		Expect(entries[0].Config.ProductProperties[".properties.continent.north-america.country"].Value).To(Equal("Canada"))
+		Expect(entries[0].Config.ProductProperties).To(HaveKey(".properties.continent.north-america.country.canada.province"))
+		Expect(entries[0].Config.ProductProperties[".properties.browser"].Value).To(Equal("Internet Explorer"))
+
+		Expect(entries[4].Config.ProductProperties[".properties.continent"].Value).To(Equal("Australia"))
+		Expect(entries[4].Config.ProductProperties).To(HaveKey(".properties.continent.australia.city"))
+		Expect(entries[4].Config.ProductProperties).ToNot(HaveKey(".properties.continent.north-america.country"))
+	})
+
+	Context("only some selectors are enumerated", func() {
+		BeforeEach(func() {
+			cmd.MatrixSelectors = []string{".properties.continent.north-america.country"}
+		})
+
+		It("uses the chosen options for the other selectors", func() {
+			entries, err := cmd.MakeConfigMatrix()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[0].File).To(Equal("product-canada.yaml"))
+			Expect(entries[1].File).To(Equal("product-mexico.yaml"))
+			Expect(entries[1].Config.ProductProperties[".properties.browser"].Value).To(Equal("Google Chrome"))
+		})
+	})
+
+	Context("a selector is given a value", func() {
+		BeforeEach(func() {
+			cmd.Values = map[string]string{".properties.continent": "Australia"}
+		})
+
+		It("does not enumerate that selector", func() {
+			entries, err := cmd.MakeConfigMatrix()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[0].File).To(Equal("product-explorer.yaml"))
+			Expect(entries[0].Config.ProductProperties[".properties.continent"].Value).To(Equal("Australia"))
+		})
+	})
+
+	Context("the number of configs is limited", func() {
+		BeforeEach(func() {
+			cmd.MaxConfigs = 3
+		})
+
+		It("only makes that many configs", func() {
+			entries, err := cmd.MakeConfigMatrix()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(3))
+		})
+	})
+
+	Context("the tile has too many combinations to make them all", func() {
+		BeforeEach(func() {
+			var blueprints []map[string]interface{}
+			for i := 0; i < 40; i++ {
+				blueprints = append(blueprints, map[string]interface{}{
+					"name":         fmt.Sprintf("selector_%d", i),
+					"configurable": true,
+					"type":         "selector",
+					"option_templates": []map[string]interface{}{
+						{"name": "one", "select_value": "one"},
+						{"name": "two", "select_value": "two"},
+					},
+				})
+			}
+			metadataCmd.LoadMetadataStub = func(target interface{}) error {
+				contents, err := yaml.Marshal(map[string]interface{}{"name": "product", "property_blueprints": blueprints})
+				Expect(err).ToNot(HaveOccurred())
+				return yaml.Unmarshal(contents, target)
+			}
+			cmd.MaxConfigs = 3
+		})
+
+		It("stops enumerating at the limit", func() {
+			entries, err := cmd.MakeConfigMatrix()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(3))
+			Expect(entries[2].Selections[38].Option).To(Equal("two"))
+			Expect(entries[2].Selections[39].Option).To(Equal("one"))
+		})
+	})
+
+	Describe("WriteConfigMatrix", func() {
+		var outputDir string
+
+		BeforeEach(func() {
+			var err error
+			outputDir, err = os.MkdirTemp("", "matrix")
+			Expect(err).ToNot(HaveOccurred())
+			cmd.MaxConfigs = 2
+		})
+
+		AfterEach(func() {
+			err := os.RemoveAll(outputDir)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("writes the config files and an index", func() {
+			entries, err := cmd.MakeConfigMatrix()
+			Expect(err).ToNot(HaveOccurred())
+
+			err = cmd.WriteConfigMatrix(filepath.Join(outputDir, "configs"), entries)
+			Expect(err).ToNot(HaveOccurred())
+
+			contents, err := os.ReadFile(filepath.Join(outputDir, "configs", "product-north-america-canada-chrome.yaml"))
+			Expect(err).ToNot(HaveOccurred())
+			config := &tileinspect.ConfigFile{}
+			Expect(yaml.Unmarshal(contents, config)).To(Succeed())
+			Expect(config.ProductProperties[".properties.browser"].Value).To(Equal("Google Chrome"))
+
+			contents, err = os.ReadFile(filepath.Join(outputDir, "configs", "index.yaml"))
+			Expect(err).ToNot(HaveOccurred())
+			var index []makeconfig.MatrixEntry
+			Expect(yaml.Unmarshal(contents, &index)).To(Succeed())
+			Expect(index).To(HaveLen(2))
+			Expect(index[1].File).To(Equal("product-north-america-canada-chrome.yaml"))
+			Expect(index[1].Selections).To(ContainElement(makeconfig.Selection{
+				Selector: ".properties.browser",
+				Option:   "chrome",
+				Value:    "Google Chrome",
+			}))
+		})
+
+		It("annotates each config file", func() {
+			cmd.Annotate = true
+			entries, err := cmd.MakeConfigMatrix()
+			Expect(err).ToNot(HaveOccurred())
+
+			err = cmd.WriteConfigMatrix(outputDir, entries)
+			Expect(err).ToNot(HaveOccurred())
+
+			contents, err := os.ReadFile(filepath.Join(outputDir, "product-north-america-canada-chrome.yaml"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring("" +
+				"  # Type: selector\n" +
+				"  # Value: matrix choice\n" +
+				"  # Options:\n" +
+				"  #   - Internet Explorer\n" +
+				"  #   - Google Chrome\n" +
+				"  .properties.browser:\n"))
+			Expect(string(contents)).To(ContainSubstring("" +
+				"  # Type: string\n" +
+				"  # Value: sample value, replace before use\n" +
+				"  .properties.continent.north-america.country.canada.province:\n"))
+		})
+
+		Context("the product is named index", func() {
+			BeforeEach(func() {
+				loadMetadata := metadataCmd.LoadMetadataStub
+				metadataCmd.LoadMetadataStub = func(target interface{}) error {
+					Expect(loadMetadata(target)).To(Succeed())
+					target.(*tileinspect.TileProperties).Name = "index"
+					target.(*tileinspect.TileProperties).PropertyBlueprints = nil
+					return nil
+				}
+			})
+
+			It("does not overwrite its config file with the index", func() {
+				entries, err := cmd.MakeConfigMatrix()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(entries).To(HaveLen(1))
+				Expect(entries[0].File).To(Equal("index-2.yaml"))
+
+				err = cmd.WriteConfigMatrix(outputDir, entries)
+				Expect(err).ToNot(HaveOccurred())
+
+				contents, err := os.ReadFile(filepath.Join(outputDir, "index-2.yaml"))
+				Expect(err).ToNot(HaveOccurred())
+				config := &tileinspect.ConfigFile{}
+				Expect(yaml.Unmarshal(contents, config)).To(Succeed())
+				Expect(config.ProductName).To(Equal("index"))
+			})
+		})
+	})
+})
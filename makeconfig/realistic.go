@@ -0,0 +1,317 @@
+package makeconfig
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	mathrand "math/rand"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/pkg/errors"
+)
+
+const (
+	realisticDomain       = "example.com"
+	maxRegexRepetitions   = 3
+	maxGenerationAttempts = 100
+	lowercaseLetters      = "abcdefghijklmnopqrstuvwxyz"
+	alphanumerics         = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// sampleGenerator makes values that satisfy the type and constraints of a property.
+// Apart from certificates and keys, the values only depend on the seed.
+type sampleGenerator struct {
+	rand *mathrand.Rand
+}
+
+func newSampleGenerator(seed int64) *sampleGenerator {
+	return &sampleGenerator{
+		rand: mathrand.New(mathrand.NewSource(seed)),
+	}
+}
+
+func (g *sampleGenerator) randomString(characters string, length int) string {
+	result := make([]byte, length)
+	for i := range result {
+		result[i] = characters[g.rand.Intn(len(characters))]
+	}
+	return string(result)
+}
+
+func (g *sampleGenerator) label() string {
+	return g.randomString(lowercaseLetters, 8)
+}
+
+func (g *sampleGenerator) ipAddress() string {
+	return fmt.Sprintf("10.%d.%d.%d", g.rand.Intn(256), g.rand.Intn(256), 10+g.rand.Intn(200))
+}
+
+func (g *sampleGenerator) integer(constraints *tileinspect.PropertyConstraints, min, max int) int {
+	if constraints != nil && constraints.Min != nil {
+		min = int(*constraints.Min)
+		if constraints.Max == nil && max < min {
+			max = min + 100
+		}
+	}
+	if constraints != nil && constraints.Max != nil {
+		max = int(*constraints.Max)
+		if constraints.Min == nil && min > max {
+			min = max - 100
+		}
+	}
+	if max <= min {
+		return min
+	}
+	return min + g.rand.Intn(max-min+1)
+}
+
+func (g *sampleGenerator) characterFromClass(ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r <= '~'; r++ {
+			if r >= '!' {
+				printable = append(printable, r)
+			}
+		}
+	}
+	if len(printable) > 0 {
+		return printable[g.rand.Intn(len(printable))]
+	}
+	if len(ranges) > 0 {
+		return ranges[0]
+	}
+	return 'a'
+}
+
+func (g *sampleGenerator) repetitions(min, max int) int {
+	if max < 0 {
+		max = min + maxRegexRepetitions
+	}
+	if min == 0 && max > 0 {
+		// Prefer non-empty values, they make for more useful samples
+		min = 1
+	}
+	return min + g.rand.Intn(max-min+1)
+}
+
+func (g *sampleGenerator) writeMatch(re *syntax.Regexp, out *strings.Builder) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		out.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		out.WriteRune(g.characterFromClass(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		out.WriteByte(lowercaseLetters[g.rand.Intn(len(lowercaseLetters))])
+	case syntax.OpCapture:
+		g.writeMatch(re.Sub[0], out)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.writeMatch(sub, out)
+		}
+	case syntax.OpAlternate:
+		g.writeMatch(re.Sub[g.rand.Intn(len(re.Sub))], out)
+	case syntax.OpStar:
+		for i := g.repetitions(0, maxRegexRepetitions); i > 0; i-- {
+			g.writeMatch(re.Sub[0], out)
+		}
+	case syntax.OpPlus:
+		for i := g.repetitions(1, -1); i > 0; i-- {
+			g.writeMatch(re.Sub[0], out)
+		}
+	case syntax.OpQuest:
+		if g.rand.Intn(2) == 0 {
+			g.writeMatch(re.Sub[0], out)
+		}
+	case syntax.OpRepeat:
+		for i := g.repetitions(re.Min, re.Max); i > 0; i-- {
+			g.writeMatch(re.Sub[0], out)
+		}
+	}
+}
+
+func (g *sampleGenerator) matchingString(patterns []string) (string, error) {
+	var parsed []*syntax.Regexp
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		if err != nil {
+			return "", errors.Wrapf(err, "could not parse the regular expression %q", pattern)
+		}
+		parsed = append(parsed, re.Simplify())
+		compiled = append(compiled, regexp.MustCompile(pattern))
+	}
+
+	for attempt := 0; attempt < maxGenerationAttempts; attempt++ {
+		var out strings.Builder
+		g.writeMatch(parsed[attempt%len(parsed)], &out)
+		candidate := out.String()
+
+		matchesAll := true
+		for _, re := range compiled {
+			if !re.MatchString(candidate) {
+				matchesAll = false
+			}
+		}
+		if matchesAll {
+			return candidate, nil
+		}
+	}
+
+	return "", errors.Errorf("could not generate a value matching %s", strings.Join(patterns, " and "))
+}
+
+func (g *sampleGenerator) text(property tileinspect.TileProperty) (string, error) {
+	if property.Constraints != nil && len(property.Constraints.MustMatchRegex) > 0 {
+		return g.matchingString(property.Constraints.MustMatchRegex)
+	}
+
+	value := strings.ReplaceAll(property.Name, "_", "-") + "-" + g.label()
+	if property.Constraints != nil && property.Constraints.MaxLength != nil && len(value) > *property.Constraints.MaxLength {
+		value = value[:*property.Constraints.MaxLength]
+	}
+	if property.Constraints != nil && property.Constraints.MinLength != nil && len(value) < *property.Constraints.MinLength {
+		value += g.randomString(lowercaseLetters, *property.Constraints.MinLength-len(value))
+	}
+	return value, nil
+}
+
+func (g *sampleGenerator) certificate() (map[string]interface{}, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil { // !branch-not-tested No good way to force this
+		return nil, errors.Wrap(err, "could not generate a private key")
+	}
+
+	hostname := "*." + realisticDomain
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(g.rand.Int63()),
+		Subject:               pkix.Name{CommonName: hostname},
+		DNSNames:              []string{hostname},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil { // !branch-not-tested No good way to force this
+		return nil, errors.Wrap(err, "could not generate a certificate")
+	}
+
+	return map[string]interface{}{
+		"cert_pem":        string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})),
+		"private_key_pem": string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+	}, nil
+}
+
+func (g *sampleGenerator) privateKey() (map[string]interface{}, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil { // !branch-not-tested No good way to force this
+		return nil, errors.Wrap(err, "could not generate a private key")
+	}
+
+	return map[string]interface{}{
+		"private_key_pem": string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+	}, nil
+}
+
+func (g *sampleGenerator) collectionItem(property tileinspect.TileProperty) (map[string]interface{}, error) {
+	item := make(map[string]interface{})
+	for _, child := range property.PropertyBlueprints {
+		if !child.Configurable {
+			continue
+		}
+
+		if child.Default != nil {
+			item[child.Name] = child.Default
+			continue
+		}
+
+		value, err := g.valueFor(child)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			item[child.Name] = value
+		}
+	}
+	return item, nil
+}
+
+// valueFor returns a value for properties without a default or a first option
+func (g *sampleGenerator) valueFor(property tileinspect.TileProperty) (interface{}, error) {
+	switch property.Type {
+	case "boolean":
+		return false, nil
+	case "integer":
+		return g.integer(property.Constraints, 1, 100), nil
+	case "port":
+		return g.integer(property.Constraints, 1024, 65535), nil
+	case "string", "text":
+		return g.text(property)
+	case "secret":
+		return map[string]interface{}{
+			"secret": g.randomString(alphanumerics, 20),
+		}, nil
+	case "network_address":
+		return g.ipAddress(), nil
+	case "network_address_list":
+		return g.ipAddress() + "," + g.ipAddress(), nil
+	case "ip_ranges":
+		ip := g.ipAddress()
+		return ip[:strings.LastIndex(ip, ".")] + ".10-" + ip[:strings.LastIndex(ip, ".")] + ".20", nil
+	case "domain":
+		return g.label() + "." + realisticDomain, nil
+	case "wildcard_domain":
+		return "*." + g.label() + "." + realisticDomain, nil
+	case "email":
+		return g.label() + "@" + realisticDomain, nil
+	case "http_url":
+		return "https://" + g.label() + "." + realisticDomain, nil
+	case "ldap_url":
+		return "ldaps://" + g.label() + "." + realisticDomain + ":636", nil
+	case "string_list":
+		return g.label() + "," + g.label(), nil
+	case "uuid":
+		return fmt.Sprintf("%08x-%04x-4%03x-8%03x-%012x", g.rand.Uint32(), g.rand.Intn(0x10000), g.rand.Intn(0x1000), g.rand.Intn(0x1000), g.rand.Int63n(0x1000000000000)), nil
+	case "simple_credentials":
+		return map[string]interface{}{
+			"identity": g.label(),
+			"password": g.randomString(alphanumerics, 20),
+		}, nil
+	case "salted_credentials":
+		return map[string]interface{}{
+			"identity": g.label(),
+			"password": g.randomString(alphanumerics, 20),
+			"salt":     g.randomString(alphanumerics, 16),
+		}, nil
+	case "rsa_cert_credentials":
+		return g.certificate()
+	case "rsa_pkey_credentials":
+		return g.privateKey()
+	case "multi_select_options":
+		if len(property.Options) > 0 {
+			return []interface{}{property.Options[0].Name}, nil
+		}
+	case "dropdown_select":
+		if len(property.Options) > 0 {
+			return property.Options[0].Name, nil
+		}
+	case "collection":
+		item, err := g.collectionItem(property)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{item}, nil
+	}
+
+	return SampleValues[property.Type], nil
+}
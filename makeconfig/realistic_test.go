@@ -0,0 +1,166 @@
+package makeconfig_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"regexp"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect/makeconfig"
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MakeConfig with realistic values", func() {
+	var (
+		cmd         *makeconfig.Config
+		metadataCmd *tileinspectfakes.FakeMetadataCmd
+	)
+
+	BeforeEach(func() {
+		metadataCmd = &tileinspectfakes.FakeMetadataCmd{}
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			name: product
+			property_blueprints:
+			  - name: org_name
+			    type: string
+			    configurable: true
+			    constraints:
+			      - must_match_regex: '^[a-z][a-z0-9-]{3,10}$'
+			        error_message: must be lowercase
+			  - name: description
+			    type: string
+			    configurable: true
+			  - name: max_conns
+			    type: integer
+			    configurable: true
+			    constraints:
+			      min: 50
+			      max: 60
+			  - name: listen_port
+			    type: port
+			    configurable: true
+			  - name: server_ip
+			    type: network_address
+			    configurable: true
+			  - name: apps_domain
+			    type: domain
+			    configurable: true
+			  - name: with_default
+			    type: string
+			    configurable: true
+			    default: keep-me
+			  - name: admin
+			    type: simple_credentials
+			    configurable: true
+			  - name: tls
+			    type: rsa_cert_credentials
+			    configurable: true
+			  - name: users
+			    type: collection
+			    configurable: true
+			    property_blueprints:
+			      - name: name
+			        type: string
+			        configurable: true
+			      - name: password
+			        type: secret
+			        configurable: true
+            `)), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+
+		cmd = &makeconfig.Config{
+			MetadataCmd: metadataCmd,
+			Realistic:   true,
+			Seed:        42,
+		}
+	})
+
+	It("generates values that satisfy the constraints", func() {
+		config, err := cmd.MakeConfig()
+		Expect(err).ToNot(HaveOccurred())
+
+		orgName := config.ProductProperties[".properties.org_name"].Value
+		Expect(orgName).To(BeAssignableToTypeOf(""))
+		Expect(regexp.MustCompile(`^[a-z][a-z0-9-]{3,10}$`).MatchString(orgName.(string))).To(BeTrue())
+
+		Expect(config.ProductProperties[".properties.description"].Value).To(HavePrefix("description-"))
+		Expect(config.ProductProperties[".properties.max_conns"].Value).To(And(BeNumerically(">=", 50), BeNumerically("<=", 60)))
+		Expect(config.ProductProperties[".properties.listen_port"].Value).To(And(BeNumerically(">=", 1024), BeNumerically("<=", 65535)))
+		Expect(net.ParseIP(config.ProductProperties[".properties.server_ip"].Value.(string))).ToNot(BeNil())
+		Expect(config.ProductProperties[".properties.apps_domain"].Value).To(HaveSuffix(".example.com"))
+		Expect(config.ProductProperties[".properties.with_default"].Value).To(Equal("keep-me"))
+		Expect(config.ProductProperties[".properties.admin"].Value).To(HaveKey("identity"))
+		Expect(config.ProductProperties[".properties.admin"].Value).To(HaveKey("password"))
+
+		users := config.ProductProperties[".properties.users"].Value
+		Expect(users).To(HaveLen(1))
+		Expect(users.([]interface{})[0]).To(HaveKey("name"))
+		Expect(users.([]interface{})[0]).To(HaveKey("password"))
+	})
+
+	It("generates a self-signed certificate", func() {
+		config, err := cmd.MakeConfig()
+		Expect(err).ToNot(HaveOccurred())
+
+		tls := config.ProductProperties[".properties.tls"].Value.(map[string]interface{})
+		block, _ := pem.Decode([]byte(tls["cert_pem"].(string)))
+		Expect(block).ToNot(BeNil())
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(certificate.DNSNames).To(ContainElement("*.example.com"))
+
+		block, _ = pem.Decode([]byte(tls["private_key_pem"].(string)))
+		Expect(block).ToNot(BeNil())
+		_, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("generates the same values for the same seed", func() {
+		first, err := cmd.MakeConfig()
+		Expect(err).ToNot(HaveOccurred())
+		second, err := cmd.MakeConfig()
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, key := range []string{".properties.org_name", ".properties.description", ".properties.max_conns", ".properties.server_ip", ".properties.admin"} {
+			Expect(second.ProductProperties[key].Value).To(Equal(first.ProductProperties[key].Value))
+		}
+
+		cmd.Seed = 7
+		third, err := cmd.MakeConfig()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(third.ProductProperties[".properties.description"].Value).ToNot(Equal(first.ProductProperties[".properties.description"].Value))
+	})
+
+	Context("the regular expression cannot be satisfied", func() {
+		BeforeEach(func() {
+			metadataCmd.LoadMetadataStub = func(target interface{}) error {
+				err := yaml.Unmarshal([]byte(heredoc.Doc(`
+				---
+				property_blueprints:
+				  - name: impossible
+				    type: string
+				    configurable: true
+				    constraints:
+				      - must_match_regex: '^a+$'
+				      - must_match_regex: '^b+$'
+                `)), &target)
+				Expect(err).ToNot(HaveOccurred())
+				return nil
+			}
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.MakeConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("invalid value for property (.properties.impossible): could not generate a value matching ^a+$ and ^b+$"))
+		})
+	})
+})
@@ -0,0 +1,156 @@
+package makeconfig
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+var unsafeVariableCharacters = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+func IsSecretType(propertyType string) bool {
+	_, isCredential := CredentialFields[propertyType]
+	return propertyType == "secret" || isCredential
+}
+
+// VariableName turns a property key (e.g. .properties.admin-password) into a variable name (e.g. properties_admin_password)
+func VariableName(propertyKey string) string {
+	return unsafeVariableCharacters.ReplaceAllString(strings.TrimPrefix(propertyKey, "."), "_")
+}
+
+// uniqueVariableName adds a numbered suffix to the variable name when another property already uses it,
+// e.g. .properties.admin-password and .properties.admin_password
+func uniqueVariableName(variableName string, variables map[string]interface{}) string {
+	uniqueName := variableName
+	for i := 2; ; i++ {
+		if _, taken := variables[uniqueName]; !taken {
+			return uniqueName
+		}
+		uniqueName = fmt.Sprintf("%s_%d", variableName, i)
+	}
+}
+
+func placeholder(variableName string) string {
+	return fmt.Sprintf("((%s))", variableName)
+}
+
+func variableShape(propertyType string) interface{} {
+	if propertyType == "secret" {
+		return ""
+	}
+
+	shape := make(map[string]interface{})
+	for _, field := range CredentialFields[propertyType] {
+		shape[field] = ""
+	}
+	return shape
+}
+
+func secretPlaceholder(propertyType string, variableName string) interface{} {
+	if propertyType == "secret" {
+		return map[string]interface{}{
+			"secret": placeholder(variableName),
+		}
+	}
+	return placeholder(variableName)
+}
+
+func replaceSecretsInCollection(propertyKey string, items []interface{}, blueprints []tileinspect.TileProperty, variables map[string]interface{}) {
+	for i, item := range items {
+		values, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, blueprint := range blueprints {
+			if _, ok := values[blueprint.Name]; !ok || !IsSecretType(blueprint.Type) {
+				continue
+			}
+
+			variableName := uniqueVariableName(VariableName(fmt.Sprintf("%s_%d_%s", propertyKey, i, blueprint.Name)), variables)
+			values[blueprint.Name] = secretPlaceholder(blueprint.Type, variableName)
+			variables[variableName] = variableShape(blueprint.Type)
+		}
+	}
+}
+
+func replaceSecretsWithVariables(config *tileinspect.ConfigFile, blueprints map[string]tileinspect.TileProperty) map[string]interface{} {
+	variables := make(map[string]interface{})
+
+	// Sorted, so that the same property always gets the same variable name
+	propertyKeys := make([]string, 0, len(config.ProductProperties))
+	for propertyKey := range config.ProductProperties {
+		propertyKeys = append(propertyKeys, propertyKey)
+	}
+	sort.Strings(propertyKeys)
+
+	for _, propertyKey := range propertyKeys {
+		property := config.ProductProperties[propertyKey]
+		blueprint, ok := blueprints[propertyKey]
+		if !ok {
+			continue
+		}
+
+		if IsSecretType(blueprint.Type) {
+			variableName := uniqueVariableName(VariableName(propertyKey), variables)
+			property.Value = secretPlaceholder(blueprint.Type, variableName)
+			variables[variableName] = variableShape(blueprint.Type)
+		} else if blueprint.Type == "collection" {
+			if items, ok := property.Value.([]interface{}); ok {
+				replaceSecretsInCollection(propertyKey, items, blueprint.PropertyBlueprints, variables)
+			}
+		}
+	}
+
+	return variables
+}
+
+// ReplaceSecretsWithVariables replaces the values of secret and credential properties with placeholders,
+// and returns the variables that need to be provided to interpolate the config file
+func ReplaceSecretsWithVariables(config *tileinspect.ConfigFile, tileProperties *tileinspect.TileProperties) map[string]interface{} {
+	return replaceSecretsWithVariables(config, tileProperties.Blueprints())
+}
+
+func (cmd *Config) WriteVarsTemplate(out io.Writer, variables map[string]interface{}) error {
+	if len(variables) == 0 {
+		variables = map[string]interface{}{}
+	}
+
+	bytes, err := yaml.Marshal(variables)
+	if err != nil { // !branch-not-tested No good way to force this
+		return errors.Wrap(err, "failed to convert vars template")
+	}
+
+	_, err = out.Write(bytes)
+	if err != nil {
+		return errors.Wrap(err, "failed to print vars template")
+	}
+	return nil
+}
+
+// WriteVarsFile writes the vars template to a file
+func (cmd *Config) WriteVarsFile(path string, variables map[string]interface{}) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the vars file: %s", path)
+	}
+
+	err = cmd.WriteVarsTemplate(file, variables)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return errors.Wrapf(err, "failed to write the vars file: %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package makeconfig_test
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect/makeconfig"
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("MakeConfig with secrets as variables", func() {
+	var (
+		cmd         *makeconfig.Config
+		metadataCmd *tileinspectfakes.FakeMetadataCmd
+	)
+
+	BeforeEach(func() {
+		metadataCmd = &tileinspectfakes.FakeMetadataCmd{}
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			name: product
+			property_blueprints:
+			  - name: hostname
+			    type: string
+			    configurable: true
+			  - name: admin-password
+			    type: secret
+			    configurable: true
+			  - name: admin
+			    type: simple_credentials
+			    configurable: true
+			  - name: tls
+			    type: rsa_cert_credentials
+			    configurable: true
+			    optional: true
+			  - name: users
+			    type: collection
+			    configurable: true
+			    property_blueprints:
+			      - name: name
+			        type: string
+			        configurable: true
+			      - name: password
+			        type: secret
+			        configurable: true
+			job_types:
+			  - name: server
+			    property_blueprints:
+			      - name: api-key
+			        type: secret
+			        configurable: true
+            `)), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+
+		cmd = &makeconfig.Config{
+			MetadataCmd:   metadataCmd,
+			SecretsAsVars: true,
+			Values: map[string]string{
+				".properties.admin-password": "do-not-print-me",
+				".properties.users":          `[{name: alice, password: hunter2}, {name: bob, password: swordfish}]`,
+			},
+		}
+	})
+
+	It("replaces secrets and credentials with placeholders", func() {
+		config, err := cmd.MakeConfig()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(config.ProductProperties[".properties.hostname"].Value).To(Equal("SAMPLE_STRING_VALUE"))
+		Expect(config.ProductProperties[".properties.admin-password"].Value).To(Equal(map[string]interface{}{"secret": "((properties_admin_password))"}))
+		Expect(config.ProductProperties[".properties.admin"].Value).To(Equal("((properties_admin))"))
+		Expect(config.ProductProperties[".properties.tls"].Value).To(Equal("((properties_tls))"))
+		Expect(config.ProductProperties[".server.api-key"].Value).To(Equal(map[string]interface{}{"secret": "((server_api_key))"}))
+		Expect(config.ProductProperties[".properties.users"].Value).To(Equal([]interface{}{
+			map[string]interface{}{"name": "alice", "password": map[string]interface{}{"secret": "((properties_users_0_password))"}},
+			map[string]interface{}{"name": "bob", "password": map[string]interface{}{"secret": "((properties_users_1_password))"}},
+		}))
+	})
+
+	It("writes a template of the variables", func() {
+		_, err := cmd.MakeConfig()
+		Expect(err).ToNot(HaveOccurred())
+
+		buffer := NewBuffer()
+		defer buffer.Close()
+		err = cmd.WriteVarsTemplate(buffer, cmd.Variables())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(buffer.Contents())).To(Equal(heredoc.Doc(`
+			properties_admin:
+			  identity: ""
+			  password: ""
+			properties_admin_password: ""
+			properties_tls:
+			  cert_pem: ""
+			  private_key_pem: ""
+			properties_users_0_password: ""
+			properties_users_1_password: ""
+			server_api_key: ""
+			`)))
+		Expect(string(buffer.Contents())).ToNot(ContainSubstring("do-not-print-me"))
+	})
+})
+
+var _ = Describe("MakeConfig with secrets whose variable names collide", func() {
+	It("adds a suffix to make the variable names unique", func() {
+		metadataCmd := &tileinspectfakes.FakeMetadataCmd{}
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			name: product
+			property_blueprints:
+			  - name: admin-password
+			    type: secret
+			    configurable: true
+			  - name: admin_password
+			    type: secret
+			    configurable: true
+			  - name: x
+			    type: collection
+			    configurable: true
+			    property_blueprints:
+			      - name: key
+			        type: secret
+			        configurable: true
+			  - name: x_0_key
+			    type: secret
+			    configurable: true
+            `)), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+
+		cmd := &makeconfig.Config{
+			MetadataCmd:   metadataCmd,
+			SecretsAsVars: true,
+			Values: map[string]string{
+				".properties.x": `[{key: secret}]`,
+			},
+		}
+		config, err := cmd.MakeConfig()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(config.ProductProperties[".properties.admin-password"].Value).To(Equal(map[string]interface{}{"secret": "((properties_admin_password))"}))
+		Expect(config.ProductProperties[".properties.admin_password"].Value).To(Equal(map[string]interface{}{"secret": "((properties_admin_password_2))"}))
+		Expect(config.ProductProperties[".properties.x"].Value).To(Equal([]interface{}{
+			map[string]interface{}{"key": map[string]interface{}{"secret": "((properties_x_0_key))"}},
+		}))
+		Expect(config.ProductProperties[".properties.x_0_key"].Value).To(Equal(map[string]interface{}{"secret": "((properties_x_0_key_2))"}))
+		Expect(cmd.Variables()).To(HaveLen(4))
+	})
+})
+
+var _ = Describe("VariableName", func() {
+	It("makes a variable name from the property key", func() {
+		Expect(makeconfig.VariableName(".properties.admin-password")).To(Equal("properties_admin_password"))
+		Expect(makeconfig.VariableName(".properties.selector.option.child")).To(Equal("properties_selector_option_child"))
+	})
+})
@@ -0,0 +1,129 @@
+package makeconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// CredentialFields lists the fields that make up the value of each credential property type
+var CredentialFields = map[string][]string{
+	"rsa_cert_credentials": {"cert_pem", "private_key_pem"},
+	"rsa_pkey_credentials": {"private_key_pem"},
+	"salted_credentials":   {"identity", "password", "salt"},
+	"simple_credentials":   {"identity", "password"},
+}
+
+func coerceToMap(property tileinspect.TileProperty, value string) (map[string]interface{}, error) {
+	var result interface{}
+	err := yaml.Unmarshal([]byte(value), &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse %q as a %s value", value, property.Type)
+	}
+
+	mapValue, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("could not convert %q to a %s value, expected a JSON or YAML map", value, property.Type)
+	}
+	return mapValue, nil
+}
+
+func coerceToList(property tileinspect.TileProperty, value string) ([]interface{}, error) {
+	var result interface{}
+	err := yaml.Unmarshal([]byte(value), &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse %q as a %s value", value, property.Type)
+	}
+
+	listValue, ok := result.([]interface{})
+	if !ok {
+		return nil, errors.Errorf("could not convert %q to a %s value, expected a JSON or YAML list", value, property.Type)
+	}
+	return listValue, nil
+}
+
+func coerceToCredential(property tileinspect.TileProperty, value string) (map[string]interface{}, error) {
+	credential, err := coerceToMap(property, value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range CredentialFields[property.Type] {
+		if _, ok := credential[field].(string); !ok {
+			return nil, errors.Errorf("could not convert %q to a %s value, missing the %q field", value, property.Type, field)
+		}
+	}
+	return credential, nil
+}
+
+func coerceToOption(property tileinspect.TileProperty, value string) (interface{}, error) {
+	for _, option := range property.Options {
+		if fmt.Sprint(option.Name) == value {
+			return option.Name, nil
+		}
+	}
+	return nil, errors.Errorf("%q is not one of the options for this %s", value, property.Type)
+}
+
+func coerceToSelectValue(property tileinspect.TileProperty, value string) (string, error) {
+	var selectValues []string
+	for _, option := range property.ChildProperties {
+		if option.SelectValue == value {
+			return value, nil
+		}
+		selectValues = append(selectValues, strconv.Quote(option.SelectValue))
+	}
+	return "", errors.Errorf("%q is not one of the options for this selector: %s", value, strings.Join(selectValues, ", "))
+}
+
+func coerceToCollection(property tileinspect.TileProperty, value string) ([]interface{}, error) {
+	items, err := coerceToList(property, value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if _, ok := item.(map[string]interface{}); !ok {
+			return nil, errors.Errorf("could not convert %q to a %s value, expected a list of maps", value, property.Type)
+		}
+	}
+	return items, nil
+}
+
+// CoerceValue converts a value given as a string into the type expected by the property
+func CoerceValue(property tileinspect.TileProperty, value string) (interface{}, error) {
+	switch property.Type {
+	case "integer", "port":
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.Errorf("could not convert %q to an integer", value)
+		}
+		return number, nil
+	case "boolean":
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.Errorf("could not convert %q to a boolean", value)
+		}
+		return boolean, nil
+	case "secret":
+		return map[string]interface{}{
+			"secret": value,
+		}, nil
+	case "dropdown_select":
+		return coerceToOption(property, value)
+	case "selector":
+		return coerceToSelectValue(property, value)
+	case "multi_select_options":
+		return coerceToList(property, value)
+	case "collection":
+		return coerceToCollection(property, value)
+	case "rsa_cert_credentials", "rsa_pkey_credentials", "salted_credentials", "simple_credentials":
+		return coerceToCredential(property, value)
+	}
+
+	return value, nil
+}
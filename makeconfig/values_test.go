@@ -0,0 +1,178 @@
+package makeconfig_test
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/makeconfig"
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CoerceValue", func() {
+	It("converts integers", func() {
+		value, err := makeconfig.CoerceValue(tileinspect.TileProperty{Type: "integer"}, "10")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal(10))
+
+		value, err = makeconfig.CoerceValue(tileinspect.TileProperty{Type: "port"}, "8443")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal(8443))
+
+		_, err = makeconfig.CoerceValue(tileinspect.TileProperty{Type: "integer"}, "ten")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`could not convert "ten" to an integer`))
+	})
+
+	It("converts booleans", func() {
+		value, err := makeconfig.CoerceValue(tileinspect.TileProperty{Type: "boolean"}, "true")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal(true))
+
+		_, err = makeconfig.CoerceValue(tileinspect.TileProperty{Type: "boolean"}, "maybe")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`could not convert "maybe" to a boolean`))
+	})
+
+	It("wraps secrets", func() {
+		value, err := makeconfig.CoerceValue(tileinspect.TileProperty{Type: "secret"}, "super-secret")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal(map[string]interface{}{"secret": "super-secret"}))
+	})
+
+	It("uses the matching dropdown option", func() {
+		property := tileinspect.TileProperty{
+			Type: "dropdown_select",
+			Options: []tileinspect.Option{
+				{Name: float64(1)},
+				{Name: float64(2)},
+			},
+		}
+		value, err := makeconfig.CoerceValue(property, "2")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal(float64(2)))
+
+		_, err = makeconfig.CoerceValue(property, "3")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`"3" is not one of the options for this dropdown_select`))
+	})
+
+	It("uses the matching selector option", func() {
+		property := tileinspect.TileProperty{
+			Type: "selector",
+			ChildProperties: []tileinspect.TileProperties{
+				{Name: "north-america", SelectValue: "North America"},
+				{Name: "australia", SelectValue: "Australia"},
+			},
+		}
+		value, err := makeconfig.CoerceValue(property, "Australia")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal("Australia"))
+
+		_, err = makeconfig.CoerceValue(property, "australia")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`"australia" is not one of the options for this selector: "North America", "Australia"`))
+	})
+
+	It("parses lists", func() {
+		value, err := makeconfig.CoerceValue(tileinspect.TileProperty{Type: "multi_select_options"}, `["one", "two"]`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal([]interface{}{"one", "two"}))
+
+		_, err = makeconfig.CoerceValue(tileinspect.TileProperty{Type: "multi_select_options"}, "one")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`could not convert "one" to a multi_select_options value, expected a JSON or YAML list`))
+	})
+
+	It("parses collections", func() {
+		value, err := makeconfig.CoerceValue(tileinspect.TileProperty{Type: "collection"}, `[{name: first}, {name: second}]`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal([]interface{}{
+			map[string]interface{}{"name": "first"},
+			map[string]interface{}{"name": "second"},
+		}))
+
+		_, err = makeconfig.CoerceValue(tileinspect.TileProperty{Type: "collection"}, `[first, second]`)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`could not convert "[first, second]" to a collection value, expected a list of maps`))
+	})
+
+	It("parses credentials", func() {
+		value, err := makeconfig.CoerceValue(tileinspect.TileProperty{Type: "simple_credentials"}, `{"identity": "admin", "password": "pa55word"}`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal(map[string]interface{}{"identity": "admin", "password": "pa55word"}))
+
+		_, err = makeconfig.CoerceValue(tileinspect.TileProperty{Type: "simple_credentials"}, `{identity: admin}`)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`could not convert "{identity: admin}" to a simple_credentials value, missing the "password" field`))
+
+		_, err = makeconfig.CoerceValue(tileinspect.TileProperty{Type: "rsa_cert_credentials"}, "a certificate")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`could not convert "a certificate" to a rsa_cert_credentials value, expected a JSON or YAML map`))
+	})
+
+	It("leaves other types as strings", func() {
+		value, err := makeconfig.CoerceValue(tileinspect.TileProperty{Type: "string"}, "10")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal("10"))
+	})
+})
+
+var _ = Describe("MakeConfig with typed value overrides", func() {
+	var (
+		cmd         *makeconfig.Config
+		metadataCmd *tileinspectfakes.FakeMetadataCmd
+	)
+
+	BeforeEach(func() {
+		metadataCmd = &tileinspectfakes.FakeMetadataCmd{}
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			property_blueprints:
+			  - name: max_conns
+			    type: integer
+			    configurable: true
+			  - name: enabled
+			    type: boolean
+			    configurable: true
+			  - name: admin
+			    type: simple_credentials
+			    configurable: true
+            `)), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+
+		cmd = &makeconfig.Config{
+			MetadataCmd: metadataCmd,
+			Values: map[string]string{
+				".properties.max_conns": "10",
+				".properties.enabled":   "true",
+				".properties.admin":     `{"identity": "admin", "password": "pa55word"}`,
+			},
+		}
+	})
+
+	It("uses the typed values", func() {
+		config, err := cmd.MakeConfig()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.ProductProperties[".properties.max_conns"].Value).To(Equal(10))
+		Expect(config.ProductProperties[".properties.enabled"].Value).To(Equal(true))
+		Expect(config.ProductProperties[".properties.admin"].Value).To(HaveKeyWithValue("identity", "admin"))
+		Expect(config.ProductProperties[".properties.admin"].Value).To(HaveKeyWithValue("password", "pa55word"))
+	})
+
+	Context("a value cannot be converted", func() {
+		BeforeEach(func() {
+			cmd.Values[".properties.max_conns"] = "lots"
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.MakeConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`invalid value for property (.properties.max_conns): could not convert "lots" to an integer`))
+		})
+	})
+})
@@ -0,0 +1,150 @@
+package makeconfig
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+var configFileSections = []string{
+	"product-name",
+	"product-properties",
+	"network-properties",
+	"resource-config",
+	"errand-config",
+}
+
+// ValuesFile holds the values for properties and the (partial) config file sections given in a values file
+type ValuesFile struct {
+	Values     map[string]interface{}
+	ConfigFile *tileinspect.ConfigFile
+}
+
+func LoadValuesFile(path string) (*ValuesFile, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the values file: %s", path)
+	}
+
+	var document map[string]interface{}
+	err = yaml.Unmarshal(contents, &document)
+	if err != nil {
+		return nil, errors.Wrap(err, "the values file does not contain valid JSON or YAML")
+	}
+
+	valuesFile := &ValuesFile{
+		Values:     make(map[string]interface{}),
+		ConfigFile: &tileinspect.ConfigFile{},
+	}
+	err = yaml.Unmarshal(contents, valuesFile.ConfigFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "the values file contains invalid config file sections")
+	}
+
+	for key, value := range valuesFile.ConfigFile.ProductProperties {
+		if value != nil {
+			valuesFile.Values[key] = value.Value
+		}
+	}
+
+	for key, value := range document {
+		if strings.HasPrefix(key, ".") {
+			valuesFile.Values[key] = value
+		} else if !stringInSlice(key, configFileSections) {
+			return nil, errors.Errorf("the values file contains an unknown key (%s)", key)
+		}
+	}
+
+	return valuesFile, nil
+}
+
+func stringInSlice(a string, list []string) bool {
+	for _, b := range list {
+		if b == a {
+			return true
+		}
+	}
+	return false
+}
+
+// isTextType is true for the property types that CoerceValue accepts any text for, e.g. string or secret
+func isTextType(propertyType string) bool {
+	switch propertyType {
+	case "integer", "port", "boolean", "dropdown_select", "selector", "multi_select_options", "collection":
+		return false
+	}
+	_, isCredential := CredentialFields[propertyType]
+	return !isCredential
+}
+
+// CoerceTypedValue converts a value that may already be typed (e.g. read from a values file) into the type expected by the property
+func CoerceTypedValue(property tileinspect.TileProperty, value interface{}) (interface{}, error) {
+	if stringValue, ok := value.(string); ok {
+		return CoerceValue(property, stringValue)
+	}
+
+	literal, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not convert %v to a %s value", value, property.Type)
+	}
+
+	if property.Type == "secret" {
+		if secret, ok := value.(map[string]interface{}); ok {
+			field, ok := secret["secret"]
+			if !ok {
+				return nil, errors.Errorf("could not convert %q to a secret value, missing the \"secret\" field", literal)
+			}
+			if _, ok := field.(string); !ok {
+				return nil, errors.Errorf("could not convert %q to a secret value, the \"secret\" field is not a string", literal)
+			}
+			return secret, nil
+		}
+	}
+
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		if isTextType(property.Type) {
+			return nil, errors.Errorf("could not convert %q to a %s value, expected a single value", literal, property.Type)
+		}
+	}
+	return CoerceValue(property, string(literal))
+}
+
+func (valuesFile *ValuesFile) applySections(config *tileinspect.ConfigFile) {
+	if valuesFile.ConfigFile.NetworkProperties != nil {
+		config.NetworkProperties = valuesFile.ConfigFile.NetworkProperties
+	}
+
+	for jobName, resource := range valuesFile.ConfigFile.ResourceConfig {
+		generated, ok := config.ResourceConfig[jobName]
+		if !ok || resource == nil {
+			continue
+		}
+		if resource.Instances != nil {
+			generated.Instances = resource.Instances
+		}
+		if resource.PersistentDisk != nil {
+			generated.PersistentDisk = resource.PersistentDisk
+		}
+		if resource.InstanceType.ID != "" {
+			generated.InstanceType = resource.InstanceType
+		}
+	}
+
+	for errandName, errand := range valuesFile.ConfigFile.ErrandConfig {
+		generated, ok := config.ErrandConfig[errandName]
+		if !ok || errand == nil {
+			continue
+		}
+		if errand.PostDeployState != nil {
+			generated.PostDeployState = errand.PostDeployState
+		}
+		if errand.PreDeleteState != nil {
+			generated.PreDeleteState = errand.PreDeleteState
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package makeconfig_test
+
+import (
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect/makeconfig"
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func makeValuesFile(contents string) (*os.File, error) {
+	valuesFile, err := os.CreateTemp("", "values-file-*.yml")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = valuesFile.Write([]byte(contents))
+	return valuesFile, err
+}
+
+var _ = Describe("MakeConfig with a values file", func() {
+	var (
+		cmd         *makeconfig.Config
+		metadataCmd *tileinspectfakes.FakeMetadataCmd
+		valuesFile  *os.File
+	)
+
+	BeforeEach(func() {
+		metadataCmd = &tileinspectfakes.FakeMetadataCmd{}
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			name: product
+			property_blueprints:
+			  - name: max_conns
+			    type: integer
+			    configurable: true
+			  - name: password
+			    type: secret
+			    configurable: true
+			  - name: api_key
+			    type: secret
+			    configurable: true
+			  - name: hostname
+			    type: string
+			    configurable: true
+			job_types:
+			  - name: server
+			    instance_definition:
+			      name: instances
+			      type: integer
+			      configurable: true
+			      default: 1
+			    resource_definitions:
+			      - name: persistent_disk
+			        type: integer
+			        configurable: true
+			        default: 1024
+			post_deploy_errands:
+			  - name: smoke-tests
+            `)), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+
+		cmd = &makeconfig.Config{
+			MetadataCmd: metadataCmd,
+		}
+	})
+
+	AfterEach(func() {
+		if valuesFile != nil {
+			err := os.Remove(valuesFile.Name())
+			Expect(err).ToNot(HaveOccurred())
+			valuesFile = nil
+		}
+	})
+
+	Context("values file with property keys", func() {
+		BeforeEach(func() {
+			var err error
+			valuesFile, err = makeValuesFile(heredoc.Doc(`
+			---
+			.properties.max_conns: 10
+			.properties.password: super-secret
+			.properties.api_key:
+			  secret: my-api-key
+			.properties.hostname: from-file
+			.properties.removed_in_this_version: ignored
+			`))
+			Expect(err).ToNot(HaveOccurred())
+			cmd.ValuesFile = valuesFile.Name()
+			cmd.Values = map[string]string{
+				".properties.hostname": "from-flag",
+			}
+		})
+
+		It("uses the values from the file, with flags taking precedence", func() {
+			config, err := cmd.MakeConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.ProductProperties[".properties.max_conns"].Value).To(Equal(10))
+			Expect(config.ProductProperties[".properties.password"].Value).To(Equal(map[string]interface{}{"secret": "super-secret"}))
+			Expect(config.ProductProperties[".properties.api_key"].Value).To(Equal(map[string]interface{}{"secret": "my-api-key"}))
+			Expect(config.ProductProperties[".properties.hostname"].Value).To(Equal("from-flag"))
+			Expect(config.ProductProperties).ToNot(HaveKey(".properties.removed_in_this_version"))
+		})
+	})
+
+	Context("values file with partial config file sections", func() {
+		BeforeEach(func() {
+			var err error
+			valuesFile, err = makeValuesFile(heredoc.Doc(`
+			---
+			product-properties:
+			  .properties.hostname:
+			    value: example.com
+			network-properties:
+			  network:
+			    name: my-network
+			  other_availability_zones:
+			    - name: az1
+			    - name: az2
+			  singleton_availability_zone:
+			    name: az1
+			resource-config:
+			  server:
+			    instances: 3
+			errand-config:
+			  smoke-tests:
+			    post-deploy-state: false
+			`))
+			Expect(err).ToNot(HaveOccurred())
+			cmd.ValuesFile = valuesFile.Name()
+		})
+
+		It("merges the sections into the config file", func() {
+			config, err := cmd.MakeConfig()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.ProductProperties[".properties.hostname"].Value).To(Equal("example.com"))
+			Expect(config.NetworkProperties.Network.Name).To(Equal("my-network"))
+			Expect(config.NetworkProperties.OtherAvailabilityZones).To(HaveLen(2))
+			Expect(config.ResourceConfig["server"].Instances).To(BeEquivalentTo(3))
+			Expect(config.ResourceConfig["server"].PersistentDisk.SizeMB).To(Equal("1024"))
+			Expect(config.ResourceConfig["server"].InstanceType.ID).To(Equal("automatic"))
+			Expect(config.ErrandConfig["smoke-tests"].PostDeployState).To(Equal(false))
+		})
+	})
+
+	Context("values file with a value that cannot be converted", func() {
+		BeforeEach(func() {
+			var err error
+			valuesFile, err = makeValuesFile(".properties.max_conns: [1, 2]")
+			Expect(err).ToNot(HaveOccurred())
+			cmd.ValuesFile = valuesFile.Name()
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.MakeConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`invalid value for property (.properties.max_conns): could not convert "[1,2]" to an integer`))
+		})
+	})
+
+	DescribeTable("values file with a map or list for a property with a single value",
+		func(contents string, expectedError string) {
+			var err error
+			valuesFile, err = makeValuesFile(contents)
+			Expect(err).ToNot(HaveOccurred())
+			cmd.ValuesFile = valuesFile.Name()
+
+			_, err = cmd.MakeConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(expectedError))
+		},
+		Entry("a secret that is not a string",
+			".properties.password: {secret: 123}",
+			`invalid value for property (.properties.password): could not convert "{\"secret\":123}" to a secret value, the "secret" field is not a string`),
+		Entry("a secret without a secret field",
+			".properties.password: {password: x}",
+			`invalid value for property (.properties.password): could not convert "{\"password\":\"x\"}" to a secret value, missing the "secret" field`),
+		Entry("a secret given as a list",
+			".properties.password: [x]",
+			`invalid value for property (.properties.password): could not convert "[\"x\"]" to a secret value, expected a single value`),
+		Entry("a map for a string",
+			".properties.hostname: {name: example.com}",
+			`invalid value for property (.properties.hostname): could not convert "{\"name\":\"example.com\"}" to a string value, expected a single value`),
+		Entry("a list for a string",
+			".properties.hostname: [example.com]",
+			`invalid value for property (.properties.hostname): could not convert "[\"example.com\"]" to a string value, expected a single value`),
+	)
+
+	Context("values file with an unknown key", func() {
+		BeforeEach(func() {
+			var err error
+			valuesFile, err = makeValuesFile("properties.max_conns: 10")
+			Expect(err).ToNot(HaveOccurred())
+			cmd.ValuesFile = valuesFile.Name()
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.MakeConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("the values file contains an unknown key (properties.max_conns)"))
+		})
+	})
+
+	Context("values file does not exist", func() {
+		BeforeEach(func() {
+			cmd.ValuesFile = "/this/path/does/not/exist.yml"
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.MakeConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to read the values file: /this/path/does/not/exist.yml: open /this/path/does/not/exist.yml: no such file or directory"))
+		})
+	})
+})
@@ -1,5 +1,15 @@
 package tileinspect
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
 //go:generate counterfeiter MetadataCmd
 type MetadataCmd interface {
 	LoadMetadata(target interface{}) error
@@ -12,21 +22,286 @@ type TileProperty struct {
 	Default            interface{} `json:"default"`
 	Optional           bool        `json:"optional"`
 	Options            []Option
-	ChildProperties    []TileProperties `json:"option_templates"`
-	PropertyBlueprints []TileProperty   `json:"property_blueprints"`
+	ChildProperties    []TileProperties     `json:"option_templates"`
+	PropertyBlueprints []TileProperty       `json:"property_blueprints"`
+	Constraints        *PropertyConstraints `json:"constraints"`
+}
+
+// PropertyConstraints can be written either as a map (e.g. min and max for integers)
+// or as a list (e.g. one or more must_match_regex entries for strings)
+type PropertyConstraints struct {
+	Min            *float64 `json:"min,omitempty"`
+	Max            *float64 `json:"max,omitempty"`
+	MinLength      *int     `json:"min_length,omitempty"`
+	MaxLength      *int     `json:"max_length,omitempty"`
+	MustMatchRegex []string `json:"must_match_regex,omitempty"`
+}
+
+type propertyConstraint struct {
+	Min            *float64 `json:"min"`
+	Max            *float64 `json:"max"`
+	MinLength      *int     `json:"min_length"`
+	MaxLength      *int     `json:"max_length"`
+	MustMatchRegex string   `json:"must_match_regex"`
+}
+
+func (c *PropertyConstraints) UnmarshalJSON(data []byte) error {
+	var constraints []propertyConstraint
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		err := json.Unmarshal(trimmed, &constraints)
+		if err != nil {
+			return err
+		}
+	} else {
+		var constraint propertyConstraint
+		err := json.Unmarshal(trimmed, &constraint)
+		if err != nil {
+			return err
+		}
+		constraints = append(constraints, constraint)
+	}
+
+	for _, constraint := range constraints {
+		if constraint.Min != nil {
+			c.Min = constraint.Min
+		}
+		if constraint.Max != nil {
+			c.Max = constraint.Max
+		}
+		if constraint.MinLength != nil {
+			c.MinLength = constraint.MinLength
+		}
+		if constraint.MaxLength != nil {
+			c.MaxLength = constraint.MaxLength
+		}
+		if constraint.MustMatchRegex != "" {
+			c.MustMatchRegex = append(c.MustMatchRegex, constraint.MustMatchRegex)
+		}
+	}
+	return nil
 }
 
 type JobType struct {
-	Name               string         `json:"name"`
-	PropertyBlueprints []TileProperty `json:"property_blueprints"`
+	Name                string         `json:"name"`
+	InstanceDefinition  *TileProperty  `json:"instance_definition"`
+	ResourceDefinitions []TileProperty `json:"resource_definitions"`
+	PropertyBlueprints  []TileProperty `json:"property_blueprints"`
+	Templates           []JobTemplate  `json:"templates"`
+	// Manifest are the BOSH properties of the jobs, usually a YAML string
+	Manifest interface{} `json:"manifest"`
+}
+
+// JobTemplate is a BOSH job of a job type, and the release that has the job
+type JobTemplate struct {
+	Name    string `json:"name"`
+	Release string `json:"release"`
+	// Manifest are the BOSH properties of this job only, usually a YAML string
+	Manifest interface{} `json:"manifest"`
+}
+
+type Errand struct {
+	Name string `json:"name"`
+	// run_default is usually on or off, which YAML reads as a boolean
+	RunDefault interface{} `json:"run_default"`
+}
+
+// TileRelease is a BOSH release declared in the metadata, file is the name of its tarball in the releases directory
+type TileRelease struct {
+	Name    string        `json:"name"`
+	File    string        `json:"file"`
+	Version VersionString `json:"version"`
 }
 
 type TileProperties struct {
-	Name               string                 `json:"name"`
-	PropertyBlueprints []TileProperty         `json:"property_blueprints"`
-	SelectValue        string                 `json:"select_value"`
-	StemcellCriteria   map[string]interface{} `json:"stemcell_criteria"`
-	JobTypes           []JobType              `json:"job_types"`
+	Name                    string            `json:"name"`
+	ProductVersion          VersionString     `json:"product_version"`
+	RequiresProductVersions []ProductVersion  `json:"requires_product_versions"`
+	ProvidesProductVersions []ProductVersion  `json:"provides_product_versions"`
+	PropertyBlueprints      []TileProperty    `json:"property_blueprints"`
+	SelectValue             string            `json:"select_value"`
+	StemcellCriteria        *StemcellCriteria `json:"stemcell_criteria"`
+	// AdditionalStemcellsCriteria are the other stemcell lines of tiles that support more than one
+	AdditionalStemcellsCriteria []StemcellCriteria `json:"additional_stemcells_criteria"`
+	Releases                    []TileRelease      `json:"releases"`
+	JobTypes                    []JobType          `json:"job_types"`
+	ServiceBroker               bool               `json:"service_broker"`
+	PostDeployErrands           []Errand           `json:"post_deploy_errands"`
+	PreDeleteErrands            []Errand           `json:"pre_delete_errands"`
+	FormTypes                   []FormType         `json:"form_types"`
+}
+
+// ProductVersion is a product with a version, or with a version constraint in requires_product_versions
+type ProductVersion struct {
+	Name    string        `json:"name"`
+	Version VersionString `json:"version"`
+}
+
+// VersionString is a version that may be written as a number in the metadata (e.g. version: 2.1)
+type VersionString string
+
+func (v *VersionString) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '"' {
+		var version string
+		err := json.Unmarshal(trimmed, &version)
+		if err != nil {
+			return err
+		}
+		*v = VersionString(version)
+		return nil
+	}
+
+	var number json.Number
+	err := json.Unmarshal(trimmed, &number)
+	if err != nil {
+		return err
+	}
+	*v = VersionString(number.String())
+	return nil
+}
+
+type StemcellCriteria struct {
+	OS          string        `json:"os"`
+	Version     VersionString `json:"version"`
+	RequiresCPI bool          `json:"requires_cpi"`
+	// EnablePatchSecurityUpdates is nil when the tile does not set it, Ops Manager then enables it
+	EnablePatchSecurityUpdates *bool `json:"enable_patch_security_updates,omitempty"`
+
+	// raw are the keys as written in the metadata, including the ones without a field
+	raw map[string]interface{}
+}
+
+func (c *StemcellCriteria) UnmarshalJSON(data []byte) error {
+	type fields StemcellCriteria
+	err := json.Unmarshal(data, (*fields)(c))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &c.raw)
+}
+
+// Fields returns the keys of the stemcell criteria as written in the metadata, with the values of the fields
+func (c StemcellCriteria) Fields() map[string]interface{} {
+	fields := make(map[string]interface{})
+	for key, value := range c.raw {
+		fields[key] = value
+	}
+
+	fields["os"] = c.OS
+	fields["version"] = c.Version
+	if _, ok := c.raw["requires_cpi"]; ok || c.RequiresCPI {
+		fields["requires_cpi"] = c.RequiresCPI
+	}
+	if c.EnablePatchSecurityUpdates != nil {
+		fields["enable_patch_security_updates"] = *c.EnablePatchSecurityUpdates
+	}
+	return fields
+}
+
+func (c StemcellCriteria) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Fields())
+}
+
+// IsFloating is true when the version only has a major version (e.g. 621), so any stemcell of that major version can be used
+func (c *StemcellCriteria) IsFloating() bool {
+	return !strings.Contains(string(c.Version), ".")
+}
+
+// stemcellVersionSegments splits a stemcell version into its numbers, e.g. 621.74 into 621 and 74
+func stemcellVersionSegments(version string) ([]int, error) {
+	var segments []int
+	for _, segment := range strings.Split(version, ".") {
+		number, err := strconv.Atoi(segment)
+		if err != nil {
+			return nil, errors.Errorf("invalid stemcell version: %q", version)
+		}
+		segments = append(segments, number)
+	}
+	return segments, nil
+}
+
+// compareStemcellVersions returns -1, 0 or 1 when a is lower than, equal to or higher than b
+func compareStemcellVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Major is the major version of the stemcell criteria, e.g. 621 for 621.74
+func (c *StemcellCriteria) Major() (int, error) {
+	segments, err := stemcellVersionSegments(string(c.Version))
+	if err != nil {
+		return 0, err
+	}
+	return segments[0], nil
+}
+
+// PatchSecurityUpdates is whether Ops Manager may use a later stemcell of the same major version than a fixed version
+func (c *StemcellCriteria) PatchSecurityUpdates() bool {
+	return c.EnablePatchSecurityUpdates == nil || *c.EnablePatchSecurityUpdates
+}
+
+// Mismatch applies the stemcell matching rules of Ops Manager: the operating system and major version need to match,
+// and a fixed version either needs to match exactly, or is the minimum when enable_patch_security_updates is on (the default).
+// It returns why the stemcell does not satisfy the criteria, or an empty string when it does.
+func (c *StemcellCriteria) Mismatch(stemcellOS string, stemcellVersion string) (string, error) {
+	if stemcellOS != c.OS {
+		return fmt.Sprintf("the tile requires the %s operating system", c.OS), nil
+	}
+
+	required, err := stemcellVersionSegments(string(c.Version))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read the stemcell criteria")
+	}
+	given, err := stemcellVersionSegments(stemcellVersion)
+	if err != nil {
+		return "", err
+	}
+
+	if given[0] != required[0] {
+		return fmt.Sprintf("the tile requires major version %d", required[0]), nil
+	}
+	if c.IsFloating() {
+		return "", nil
+	}
+
+	comparison := compareStemcellVersions(given, required)
+	if !c.PatchSecurityUpdates() && comparison != 0 {
+		return fmt.Sprintf("the tile requires exactly version %s because enable_patch_security_updates is off", c.Version), nil
+	}
+	if comparison < 0 {
+		return fmt.Sprintf("the tile requires version %s or later", c.Version), nil
+	}
+	return "", nil
+}
+
+type FormType struct {
+	Name           string          `json:"name"`
+	Label          string          `json:"label"`
+	Description    string          `json:"description"`
+	PropertyInputs []PropertyInput `json:"property_inputs"`
+}
+
+type PropertyInput struct {
+	Reference              string          `json:"reference"`
+	Label                  string          `json:"label"`
+	Description            string          `json:"description"`
+	PropertyInputs         []PropertyInput `json:"property_inputs"`
+	SelectorPropertyInputs []PropertyInput `json:"selector_property_inputs"`
 }
 
 type Option struct {
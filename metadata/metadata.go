@@ -6,13 +6,14 @@ import (
 	"io"
 	"os"
 	"regexp"
+	"strings"
 
 	"github.com/cf-platform-eng/tileinspect"
 	"github.com/ghodss/yaml"
 	. "github.com/pkg/errors"
+	yamlv3 "go.yaml.in/yaml/v3"
 )
 
-
 type Config struct {
 	tileinspect.TileConfig
 	// duplicate choice required by go-flags
@@ -56,25 +57,37 @@ func (cmd *Config) dumpFile(zipFile *zip.File, out io.Writer) error {
 	}
 }
 
-func (cmd *Config) findMetadataFile() (*zip.File, error) {
-	tile, err := zip.OpenReader(cmd.Tile)
+// OpenTile opens the tile, which is a zip file
+func OpenTile(tile string) (*zip.ReadCloser, error) {
+	reader, err := zip.OpenReader(tile)
+	if err != nil {
+		return nil, Wrapf(err, "could not unzip %s", tile)
+	}
+	return reader, nil
+}
+
+// findMetadataFile returns the metadata file and the opened tile, which needs to be closed after reading the file
+func (cmd *Config) findMetadataFile() (*zip.ReadCloser, *zip.File, error) {
+	tile, err := OpenTile(cmd.Tile)
 	if err != nil {
-		return nil, Wrapf(err, "could not unzip %s", cmd.Tile)
+		return nil, nil, err
 	}
 
 	metadataFile := findInZip(`metadata/.*\.yml`, tile)
 	if metadataFile == nil {
-		return nil, errors.New("metadata file not found")
+		_ = tile.Close()
+		return nil, nil, errors.New("metadata file not found")
 	}
 
-	return metadataFile, nil
+	return tile, metadataFile, nil
 }
 
 func (cmd *Config) LoadMetadata(target interface{}) error {
-	metadataFile, err := cmd.findMetadataFile()
+	tile, metadataFile, err := cmd.findMetadataFile()
 	if err != nil {
 		return err
 	}
+	defer tile.Close()
 
 	file, err := metadataFile.Open()
 	if err != nil {
@@ -87,7 +100,7 @@ func (cmd *Config) LoadMetadata(target interface{}) error {
 		return Wrap(err, "could not read the metadata file")
 	}
 
-	err = yaml.Unmarshal(buf, &target)
+	err = yaml.Unmarshal(keepVersionText(buf), &target)
 	if err != nil {
 		return Wrap(err, "could not load the metadata file")
 	}
@@ -95,11 +108,96 @@ func (cmd *Config) LoadMetadata(target interface{}) error {
 	return nil
 }
 
+// versionSections are the top-level keys of the metadata whose entries have a version, besides the *_product_versions lists
+var versionSections = []string{
+	"stemcell_criteria",
+	"additional_stemcells_criteria",
+	"releases",
+}
+
+func mappingValue(node *yamlv3.Node, key string) *yamlv3.Node {
+	if node == nil || node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func quoteNumber(node *yamlv3.Node) bool {
+	if node == nil || node.Kind != yamlv3.ScalarNode || (node.Tag != "!!float" && node.Tag != "!!int") {
+		return false
+	}
+	node.Tag = "!!str"
+	node.Style = yamlv3.DoubleQuotedStyle
+	return true
+}
+
+// quoteEntryVersions quotes the version of a map, or of each map in a list
+func quoteEntryVersions(node *yamlv3.Node) bool {
+	if node == nil {
+		return false
+	}
+	if node.Kind == yamlv3.MappingNode {
+		return quoteNumber(mappingValue(node, "version"))
+	}
+
+	changed := false
+	if node.Kind == yamlv3.SequenceNode {
+		for _, entry := range node.Content {
+			changed = quoteNumber(mappingValue(entry, "version")) || changed
+		}
+	}
+	return changed
+}
+
+// quoteVersions quotes the product_version, and the versions of the stemcell criteria, releases and *_product_versions,
+// but not keys named version elsewhere, e.g. in property defaults or manifests
+func quoteVersions(document *yamlv3.Node) bool {
+	if document.Kind != yamlv3.DocumentNode || len(document.Content) == 0 {
+		return false
+	}
+	root := document.Content[0]
+
+	changed := quoteNumber(mappingValue(root, "product_version"))
+	for _, section := range versionSections {
+		changed = quoteEntryVersions(mappingValue(root, section)) || changed
+	}
+	if root.Kind == yamlv3.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if strings.HasSuffix(root.Content[i].Value, "_product_versions") {
+				changed = quoteEntryVersions(root.Content[i+1]) || changed
+			}
+		}
+	}
+	return changed
+}
+
+// keepVersionText quotes the versions that are written as numbers, so version: 621.10 is not read as 621.1
+func keepVersionText(metadata []byte) []byte {
+	var document yamlv3.Node
+	err := yamlv3.Unmarshal(metadata, &document)
+	if err != nil || !quoteVersions(&document) {
+		// Invalid YAML is reported when loading the metadata
+		return metadata
+	}
+
+	quoted, err := yamlv3.Marshal(&document)
+	if err != nil { // !branch-not-tested No good way to force this
+		return metadata
+	}
+	return quoted
+}
+
 func (cmd *Config) WriteMetadata(out io.Writer) error {
-	metadataFile, err := cmd.findMetadataFile()
+	tile, metadataFile, err := cmd.findMetadataFile()
 	if err != nil {
 		return err
 	}
+	defer tile.Close()
 
 	err = cmd.dumpFile(metadataFile, out)
 	if err != nil {
@@ -158,6 +158,63 @@ var _ = Describe("LoadMetadata", func() {
 		})
 	})
 
+	Context("Versions written as numbers", func() {
+		var config metadata.Config
+		BeforeEach(func() {
+			var err error
+			tile, err = CreateTestTileWithMetadata(heredoc.Doc(`
+			---
+			name: my-super-tile
+			product_version: 2.10
+			stemcell_criteria:
+			  os: ubuntu-xenial
+			  version: 621.10
+			additional_stemcells_criteria:
+			  - os: windows2019
+			    version: 2019.40
+			requires_product_versions:
+			  - name: cf
+			    version: 2.10.0
+			provides_product_versions:
+			  - name: mysql
+			    version: 2.10
+			releases:
+			  - name: mysql
+			    file: mysql-36.tgz
+			    version: 36
+			property_blueprints:
+			  - name: ratio
+			    type: string
+			    default: 0.50
+			  - name: client
+			    type: string
+			    default:
+			      version: 1.10
+            `))
+			Expect(err).ToNot(HaveOccurred())
+
+			config = metadata.Config{
+				TileConfig: tileinspect.TileConfig{
+					Tile: tile.Name(),
+				},
+			}
+		})
+
+		It("keeps the text of the versions", func() {
+			tileProperties := &tileinspect.TileProperties{}
+			err := config.LoadMetadata(tileProperties)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tileProperties.ProductVersion).To(Equal(tileinspect.VersionString("2.10")))
+			Expect(tileProperties.StemcellCriteria.Version).To(Equal(tileinspect.VersionString("621.10")))
+			Expect(tileProperties.RequiresProductVersions[0].Version).To(Equal(tileinspect.VersionString("2.10.0")))
+			Expect(tileProperties.Releases[0].Version).To(Equal(tileinspect.VersionString("36")))
+			Expect(tileProperties.AdditionalStemcellsCriteria[0].Version).To(Equal(tileinspect.VersionString("2019.40")))
+			Expect(tileProperties.ProvidesProductVersions[0].Version).To(Equal(tileinspect.VersionString("2.10")))
+			Expect(tileProperties.PropertyBlueprints[0].Default).To(Equal(0.5))
+			Expect(tileProperties.PropertyBlueprints[1].Default).To(Equal(map[string]interface{}{"version": 1.1}))
+		})
+	})
+
 	Context("Invalid metadata file inside tile", func() {
 		var config metadata.Config
 		BeforeEach(func() {
@@ -0,0 +1,253 @@
+package releases
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// JobSpec is the job.MF file of a BOSH job, inside the jobs directory of a release tarball
+type JobSpec struct {
+	Name       string                 `json:"name"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+func readJobSpec(jobTarball io.Reader) (*JobSpec, error) {
+	gzipReader, err := gzip.NewReader(jobTarball)
+	if err != nil {
+		return nil, errors.Wrap(err, "the job is not a gzipped tarball")
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, errors.New("the job does not contain a job.MF")
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the job tarball")
+		}
+
+		if path.Clean(header.Name) != "job.MF" {
+			continue
+		}
+
+		contents, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read job.MF")
+		}
+
+		spec := &JobSpec{}
+		err = yaml.Unmarshal(contents, spec)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse job.MF")
+		}
+		return spec, nil
+	}
+}
+
+// releaseContents are the release.MF and the job specs of a release tarball
+type releaseContents struct {
+	manifest *ReleaseManifest
+	jobSpecs map[string]*JobSpec
+	// jobsErr is set when the jobs failed to read, which does not stop the release.MF from being read
+	jobsErr error
+}
+
+// readReleaseContents reads the release.MF and the job.MF of each job in a single pass over a release tarball
+func readReleaseContents(tarball io.Reader) (*releaseContents, error) {
+	gzipReader, err := gzip.NewReader(tarball)
+	if err != nil {
+		return nil, errors.Wrap(err, "the release is not a gzipped tarball")
+	}
+	defer gzipReader.Close()
+
+	contents := &releaseContents{jobSpecs: make(map[string]*JobSpec)}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if contents.manifest == nil {
+				return nil, errors.Wrap(err, "failed to read the release tarball")
+			}
+			contents.jobsErr = errors.Wrap(err, "failed to read the release tarball")
+			break
+		}
+
+		name := path.Clean(header.Name)
+		if name == "release.MF" {
+			contents.manifest, err = parseReleaseManifest(tarReader)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if contents.jobsErr != nil || path.Dir(name) != "jobs" || !strings.HasSuffix(name, ".tgz") {
+			continue
+		}
+		job := strings.TrimSuffix(path.Base(name), ".tgz")
+		spec, err := readJobSpec(tarReader)
+		if err != nil {
+			contents.jobsErr = errors.Wrapf(err, "could not read job %s", job)
+			continue
+		}
+		contents.jobSpecs[job] = spec
+	}
+
+	if contents.manifest == nil {
+		return nil, errors.New("the release does not contain a release.MF")
+	}
+	if contents.jobsErr != nil {
+		contents.jobSpecs = nil
+	}
+	return contents, nil
+}
+
+// manifestProperties returns the dotted paths of the properties set by a job manifest, e.g. mysql.port
+func manifestProperties(manifest interface{}) ([]string, error) {
+	if text, ok := manifest.(string); ok {
+		var parsed interface{}
+		err := yaml.Unmarshal([]byte(text), &parsed)
+		if err != nil {
+			return nil, errors.Wrap(err, "the manifest is not valid YAML")
+		}
+		manifest = parsed
+	}
+	if manifest == nil {
+		return nil, nil
+	}
+
+	properties, ok := manifest.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("the manifest is not a map of properties")
+	}
+	return flattenProperties("", properties), nil
+}
+
+func flattenProperties(prefix string, properties map[string]interface{}) []string {
+	var paths []string
+	for key, value := range properties {
+		property := prefix + key
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			paths = append(paths, flattenProperties(property+".", nested)...)
+		} else {
+			paths = append(paths, property)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// hasProperty is true when the property, a hash inside it, or a hash of its properties is in the job spec
+func (spec *JobSpec) hasProperty(property string) bool {
+	for name := range spec.Properties {
+		if property == name || strings.HasPrefix(property, name+".") || strings.HasPrefix(name, property+".") {
+			return true
+		}
+	}
+	return false
+}
+
+type templateJob struct {
+	template tileinspect.JobTemplate
+	spec     *JobSpec
+}
+
+// checkJobTypes checks that the templates of each job type are jobs of the declared releases,
+// and that the properties in their manifests are in the job specs
+func checkJobTypes(jobTypes []tileinspect.JobType, releases map[string]*Release, jobSpecs func(release string) (map[string]*JobSpec, error)) []string {
+	var discrepancies []string
+	for _, jobType := range jobTypes {
+		var jobs []templateJob
+		complete := true
+		for _, template := range jobType.Templates {
+			release, ok := releases[template.Release]
+			if !ok {
+				discrepancies = append(discrepancies, fmt.Sprintf("job type %s uses job %s from release %s, which is not declared in the metadata", jobType.Name, template.Name, template.Release))
+				complete = false
+				continue
+			}
+			if release == nil {
+				// The release tarball is missing or broken, which is already reported
+				complete = false
+				continue
+			}
+			if !stringInSlice(template.Name, release.Jobs) {
+				discrepancies = append(discrepancies, fmt.Sprintf("job type %s uses job %s, which is not in release %s", jobType.Name, template.Name, template.Release))
+				complete = false
+				continue
+			}
+
+			specs, err := jobSpecs(template.Release)
+			if err != nil {
+				discrepancies = append(discrepancies, err.Error())
+				complete = false
+				continue
+			}
+			if specs == nil {
+				// The jobs of the release failed to read, which is already reported
+				complete = false
+				continue
+			}
+			spec, ok := specs[template.Name]
+			if !ok {
+				discrepancies = append(discrepancies, fmt.Sprintf("job type %s uses job %s, which is in the release.MF of %s but not in its jobs directory", jobType.Name, template.Name, template.Release))
+				complete = false
+				continue
+			}
+			jobs = append(jobs, templateJob{template: template, spec: spec})
+
+			properties, err := manifestProperties(template.Manifest)
+			if err != nil {
+				discrepancies = append(discrepancies, fmt.Sprintf("job type %s has an invalid manifest for job %s: %s", jobType.Name, template.Name, err.Error()))
+				continue
+			}
+			for _, property := range properties {
+				if !spec.hasProperty(property) {
+					discrepancies = append(discrepancies, fmt.Sprintf("job type %s sets property %s, which is not in the spec of job %s", jobType.Name, property, template.Name))
+				}
+			}
+		}
+
+		properties, err := manifestProperties(jobType.Manifest)
+		if err != nil {
+			discrepancies = append(discrepancies, fmt.Sprintf("job type %s has an invalid manifest: %s", jobType.Name, err.Error()))
+			continue
+		}
+		if len(jobType.Templates) == 0 {
+			for _, property := range properties {
+				discrepancies = append(discrepancies, fmt.Sprintf("job type %s sets property %s, but has no jobs", jobType.Name, property))
+			}
+			continue
+		}
+		if !complete {
+			// Properties of the job type manifest may belong to the jobs that could not be checked
+			continue
+		}
+		for _, property := range properties {
+			found := false
+			var names []string
+			for _, job := range jobs {
+				names = append(names, job.template.Name)
+				found = found || job.spec.hasProperty(property)
+			}
+			if !found {
+				discrepancies = append(discrepancies, fmt.Sprintf("job type %s sets property %s, which is not in the spec of any of its jobs (%s)", jobType.Name, property, strings.Join(names, ", ")))
+			}
+		}
+	}
+	return discrepancies
+}
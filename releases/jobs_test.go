@@ -0,0 +1,193 @@
+package releases_test
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/releases"
+	"github.com/cf-platform-eng/tileinspect/testhelpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func makeJobTarball(spec string) string {
+	return string(MakeReleaseTarball(map[string]string{
+		"./job.MF":           spec,
+		"./templates/ctl.sh": "#!/bin/bash",
+	}))
+}
+
+var _ = Describe("FindDiscrepancies reading job specs", func() {
+	findDiscrepancies := func(files map[string]string) []string {
+		cmd := &releases.Config{
+			TileConfig: tileinspect.TileConfig{
+				Tile: testhelpers.MakeTileFromMap(map[string][]byte{
+					"releases/mysql-1.0.0.tgz": MakeReleaseTarball(files),
+				}),
+			},
+			MetadataCmd: MakeMetadataCmd(heredoc.Doc(`
+				releases:
+				  - {name: mysql, file: mysql-1.0.0.tgz, version: 1.0.0}
+				job_types:
+				  - name: database
+				    templates:
+				      - name: mysql
+				        release: mysql
+				        manifest: |
+				          mysql:
+				            port: 3306
+				`)),
+		}
+		discrepancies, err := cmd.FindDiscrepancies()
+		Expect(err).ToNot(HaveOccurred())
+		return discrepancies
+	}
+
+	It("reads the job.MF of each job in the jobs directory", func() {
+		Expect(findDiscrepancies(map[string]string{
+			"./release.MF":       "name: mysql\nversion: 1.0.0\njobs:\n  - name: mysql\n",
+			"./jobs/mysql.tgz":   makeJobTarball("name: mysql\nproperties:\n  mysql.port:\n    default: 3306\n"),
+			"./packages/db.tgz":  "not a job",
+			"./jobs/nested/x.MF": "not a job",
+		})).To(BeEmpty())
+	})
+
+	It("reports a job that does not have a job.MF", func() {
+		Expect(findDiscrepancies(map[string]string{
+			"./release.MF":     "name: mysql\nversion: 1.0.0\njobs:\n  - name: mysql\n",
+			"./jobs/mysql.tgz": string(MakeReleaseTarball(map[string]string{"./monit": ""})),
+		})).To(Equal([]string{
+			"could not read the jobs of releases/mysql-1.0.0.tgz: could not read job mysql: the job does not contain a job.MF",
+		}))
+	})
+
+	It("reports a release without a release.MF", func() {
+		Expect(findDiscrepancies(map[string]string{
+			"./jobs/mysql.tgz": makeJobTarball("name: mysql\n"),
+		})).To(Equal([]string{
+			"could not read releases/mysql-1.0.0.tgz: the release does not contain a release.MF",
+		}))
+	})
+})
+
+var _ = Describe("FindDiscrepancies for job types", func() {
+	var cmd *releases.Config
+
+	BeforeEach(func() {
+		cmd = &releases.Config{
+			TileConfig: tileinspect.TileConfig{
+				Tile: testhelpers.MakeTileFromMap(map[string][]byte{
+					"releases/mysql-1.0.0.tgz": MakeReleaseTarball(map[string]string{
+						"./release.MF": heredoc.Doc(`
+							name: mysql
+							version: 1.0.0
+							jobs:
+							  - name: mysql
+							  - name: smoke-tests
+							  - name: ghost
+							`),
+						"./jobs/mysql.tgz": makeJobTarball(heredoc.Doc(`
+							name: mysql
+							properties:
+							  mysql.port:
+							    default: 3306
+							  mysql.users:
+							    description: a hash of users
+							  mysql.tls.certificate: {}
+							`)),
+						"./jobs/smoke-tests.tgz": makeJobTarball(heredoc.Doc(`
+							name: smoke-tests
+							properties:
+							  smoke.enabled: {}
+							`)),
+					}),
+				}),
+			},
+			MetadataCmd: MakeMetadataCmd(heredoc.Doc(`
+				releases:
+				  - {name: mysql, file: mysql-1.0.0.tgz, version: 1.0.0}
+				  - {name: missing, file: missing-1.0.0.tgz, version: 1.0.0}
+				job_types:
+				  - name: database
+				    templates:
+				      - name: mysql
+				        release: mysql
+				        manifest: |
+				          mysql:
+				            port: 3306
+				            users:
+				              admin: (( .properties.admin.value ))
+				            tls: (( .properties.tls.value ))
+				            typo: true
+				      - name: smoke-tests
+				        release: mysql
+				    manifest: |
+				      smoke:
+				        enabled: true
+				      unknown: value
+				  - name: proxy
+				    templates:
+				      - {name: proxy, release: mysql}
+				      - {name: router, release: routing}
+				      - {name: agent, release: missing}
+				    manifest: |
+				      anything: true
+				  - name: errand
+				    templates:
+				      - name: smoke-tests
+				        release: mysql
+				        manifest: |
+				          - not a map
+				      - {name: ghost, release: mysql}
+				  - name: placeholder
+				    manifest: |
+				      mysql:
+				        port: 3306
+				`)),
+		}
+	})
+
+	It("reports templates and properties that do not exist", func() {
+		discrepancies, err := cmd.FindDiscrepancies()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(discrepancies).To(Equal([]string{
+			"release missing 1.0.0 is declared in the metadata, but releases/missing-1.0.0.tgz is not in the tile",
+			"job type database sets property mysql.typo, which is not in the spec of job mysql",
+			"job type database sets property unknown, which is not in the spec of any of its jobs (mysql, smoke-tests)",
+			"job type proxy uses job proxy, which is not in release mysql",
+			"job type proxy uses job router from release routing, which is not declared in the metadata",
+			"job type errand has an invalid manifest for job smoke-tests: the manifest is not a map of properties",
+			"job type errand uses job ghost, which is in the release.MF of mysql but not in its jobs directory",
+			"job type placeholder sets property mysql.port, but has no jobs",
+		}))
+	})
+
+	It("reports a release with a broken job once", func() {
+		cmd.Tile = testhelpers.MakeTileFromMap(map[string][]byte{
+			"releases/mysql-1.0.0.tgz": MakeReleaseTarball(map[string]string{
+				"./release.MF": heredoc.Doc(`
+					name: mysql
+					version: 1.0.0
+					jobs:
+					  - name: mysql
+					`),
+				"./jobs/mysql.tgz": "not a tarball",
+			}),
+		})
+		cmd.MetadataCmd = MakeMetadataCmd(heredoc.Doc(`
+			releases:
+			  - {name: mysql, file: mysql-1.0.0.tgz, version: 1.0.0}
+			job_types:
+			  - name: database
+			    templates:
+			      - {name: mysql, release: mysql}
+			  - name: backup
+			    templates:
+			      - {name: mysql, release: mysql}
+			`))
+
+		discrepancies, err := cmd.FindDiscrepancies()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(discrepancies).To(HaveLen(1))
+		Expect(discrepancies[0]).To(HavePrefix("could not read the jobs of releases/mysql-1.0.0.tgz: could not read job mysql: the job is not a gzipped tarball"))
+	})
+})
@@ -0,0 +1,227 @@
+package releases
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/metadata"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	tileinspect.TileConfig
+	// duplicate choice required by go-flags
+	// nolint:staticcheck
+	Format      string `long:"format" short:"f" description:"output file type" choice:"yaml" choice:"json" default:"yaml"`
+	Verify      bool   `long:"verify" description:"check the releases in the tile against the releases declared in the metadata"`
+	MetadataCmd tileinspect.MetadataCmd
+}
+
+// ReleaseManifest is the release.MF file of a BOSH release tarball
+type ReleaseManifest struct {
+	Name               string                    `json:"name"`
+	Version            tileinspect.VersionString `json:"version"`
+	CommitHash         string                    `json:"commit_hash"`
+	UncommittedChanges bool                      `json:"uncommitted_changes"`
+	Jobs               []ReleaseJob              `json:"jobs"`
+	Packages           []ReleasePackage          `json:"packages"`
+	CompiledPackages   []ReleasePackage          `json:"compiled_packages"`
+}
+
+type ReleaseJob struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Fingerprint string `json:"fingerprint"`
+	SHA1        string `json:"sha1"`
+}
+
+type ReleasePackage struct {
+	Name         string   `json:"name"`
+	Version      string   `json:"version"`
+	Fingerprint  string   `json:"fingerprint"`
+	SHA1         string   `json:"sha1"`
+	Dependencies []string `json:"dependencies"`
+	// Stemcell is only set for compiled packages, e.g. ubuntu-xenial/621.74
+	Stemcell string `json:"stemcell,omitempty"`
+}
+
+// Release is a release tarball found in the tile
+type Release struct {
+	File       string   `json:"file"`
+	Name       string   `json:"name"`
+	Version    string   `json:"version"`
+	CommitHash string   `json:"commit_hash"`
+	Jobs       []string `json:"jobs"`
+	Packages   []string `json:"packages"`
+	Compiled   bool     `json:"compiled"`
+	Stemcell   string   `json:"stemcell,omitempty"`
+
+	Manifest *ReleaseManifest `json:"-"`
+}
+
+var releaseTarball = regexp.MustCompile(`^releases/[^/]+\.(tgz|tar\.gz)$`)
+
+// ReadReleaseManifest reads release.MF from a release tarball, without reading the rest of the tarball
+func ReadReleaseManifest(tarball io.Reader) (*ReleaseManifest, error) {
+	gzipReader, err := gzip.NewReader(tarball)
+	if err != nil {
+		return nil, errors.Wrap(err, "the release is not a gzipped tarball")
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, errors.New("the release does not contain a release.MF")
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the release tarball")
+		}
+
+		if path.Clean(header.Name) != "release.MF" {
+			continue
+		}
+		return parseReleaseManifest(tarReader)
+	}
+}
+
+func parseReleaseManifest(in io.Reader) (*ReleaseManifest, error) {
+	contents, err := io.ReadAll(in)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read release.MF")
+	}
+
+	manifest := &ReleaseManifest{}
+	err = yaml.Unmarshal(contents, manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse release.MF")
+	}
+	return manifest, nil
+}
+
+func newRelease(file string, manifest *ReleaseManifest) *Release {
+	release := &Release{
+		File:       file,
+		Name:       manifest.Name,
+		Version:    string(manifest.Version),
+		CommitHash: manifest.CommitHash,
+		Jobs:       []string{},
+		Packages:   []string{},
+		Compiled:   len(manifest.CompiledPackages) > 0,
+		Manifest:   manifest,
+	}
+
+	for _, job := range manifest.Jobs {
+		release.Jobs = append(release.Jobs, job.Name)
+	}
+
+	var stemcells []string
+	for _, pkg := range append(manifest.Packages, manifest.CompiledPackages...) {
+		release.Packages = append(release.Packages, pkg.Name)
+		if pkg.Stemcell != "" && !stringInSlice(pkg.Stemcell, stemcells) {
+			stemcells = append(stemcells, pkg.Stemcell)
+		}
+	}
+	release.Stemcell = strings.Join(stemcells, ", ")
+
+	return release
+}
+
+func stringInSlice(a string, list []string) bool {
+	for _, b := range list {
+		if b == a {
+			return true
+		}
+	}
+	return false
+}
+
+func readRelease(file *zip.File) (*Release, error) {
+	tarball, err := file.Open()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", file.Name)
+	}
+	defer tarball.Close()
+
+	manifest, err := ReadReleaseManifest(tarball)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", file.Name)
+	}
+	return newRelease(path.Base(file.Name), manifest), nil
+}
+
+// LoadReleases reads the release.MF of each release tarball in the releases directory of the tile
+func (cmd *Config) LoadReleases() ([]*Release, error) {
+	tile, err := metadata.OpenTile(cmd.Tile)
+	if err != nil {
+		return nil, err
+	}
+	defer tile.Close()
+
+	var releases []*Release
+	for _, file := range tile.File {
+		if !releaseTarball.MatchString(file.Name) {
+			continue
+		}
+
+		release, err := readRelease(file)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].File < releases[j].File
+	})
+	return releases, nil
+}
+
+func (cmd *Config) WriteReleases(out io.Writer) error {
+	releases, err := cmd.LoadReleases()
+	if err != nil {
+		return err
+	}
+	if releases == nil {
+		releases = []*Release{}
+	}
+
+	var bytes []byte
+	if cmd.Format == "json" {
+		bytes, err = json.Marshal(releases)
+	} else {
+		bytes, err = yaml.Marshal(releases)
+	}
+	if err != nil { // !branch-not-tested No good way to force this
+		return errors.Wrap(err, "failed to convert the releases")
+	}
+
+	_, err = out.Write(bytes)
+	if err != nil {
+		return errors.Wrap(err, "failed to print the releases")
+	}
+	return nil
+}
+
+func (cmd *Config) Execute(args []string) error {
+	if cmd.Verify {
+		cmd.MetadataCmd = &metadata.Config{
+			TileConfig: tileinspect.TileConfig{
+				Tile: cmd.Tile,
+			},
+		}
+		return cmd.VerifyReleases(os.Stdout)
+	}
+	return cmd.WriteReleases(os.Stdout)
+}
@@ -0,0 +1,143 @@
+package releases_test
+
+import (
+	"encoding/json"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/releases"
+	"github.com/cf-platform-eng/tileinspect/testhelpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("LoadReleases", func() {
+	var cmd *releases.Config
+
+	BeforeEach(func() {
+		tile := testhelpers.MakeTileFromMap(map[string][]byte{
+			"metadata/metadata.yml": []byte("name: product\n"),
+			"releases/mysql-36.10.0.tgz": MakeReleaseTarball(map[string]string{
+				"./jobs/mysql.tgz": "not a real job",
+				"./release.MF": heredoc.Doc(`
+					name: mysql
+					version: 36.10.0
+					commit_hash: abc1234
+					uncommitted_changes: false
+					jobs:
+					  - name: mysql
+					    version: 1a2b
+					  - name: smoke-tests
+					    version: 3c4d
+					packages:
+					  - name: mariadb
+					    version: 5e6f
+					`),
+			}),
+			"releases/routing-0.2-ubuntu-xenial-621.74.tgz": MakeReleaseTarball(map[string]string{
+				"release.MF": heredoc.Doc(`
+					name: routing
+					version: 0.2
+					commit_hash: def5678
+					jobs:
+					  - name: gorouter
+					compiled_packages:
+					  - name: gorouter
+					    stemcell: ubuntu-xenial/621.74
+					  - name: golang
+					    stemcell: ubuntu-xenial/621.74
+					`),
+			}),
+		})
+
+		cmd = &releases.Config{
+			TileConfig: tileinspect.TileConfig{Tile: tile},
+			Format:     "yaml",
+		}
+	})
+
+	It("reads the release.MF of each release", func() {
+		loaded, err := cmd.LoadReleases()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(HaveLen(2))
+
+		Expect(loaded[0].File).To(Equal("mysql-36.10.0.tgz"))
+		Expect(loaded[0].Name).To(Equal("mysql"))
+		Expect(loaded[0].Version).To(Equal("36.10.0"))
+		Expect(loaded[0].CommitHash).To(Equal("abc1234"))
+		Expect(loaded[0].Jobs).To(Equal([]string{"mysql", "smoke-tests"}))
+		Expect(loaded[0].Packages).To(Equal([]string{"mariadb"}))
+		Expect(loaded[0].Compiled).To(BeFalse())
+		Expect(loaded[0].Stemcell).To(BeEmpty())
+
+		Expect(loaded[1].Name).To(Equal("routing"))
+		Expect(loaded[1].Version).To(Equal("0.2"))
+		Expect(loaded[1].Packages).To(Equal([]string{"gorouter", "golang"}))
+		Expect(loaded[1].Compiled).To(BeTrue())
+		Expect(loaded[1].Stemcell).To(Equal("ubuntu-xenial/621.74"))
+	})
+
+	It("writes the releases", func() {
+		cmd.Format = "json"
+		buffer := NewBuffer()
+		defer buffer.Close()
+		err := cmd.WriteReleases(buffer)
+		Expect(err).ToNot(HaveOccurred())
+
+		var written []map[string]interface{}
+		err = json.Unmarshal(buffer.Contents(), &written)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(written).To(HaveLen(2))
+		Expect(written[1]).To(Equal(map[string]interface{}{
+			"file":        "routing-0.2-ubuntu-xenial-621.74.tgz",
+			"name":        "routing",
+			"version":     "0.2",
+			"commit_hash": "def5678",
+			"jobs":        []interface{}{"gorouter"},
+			"packages":    []interface{}{"gorouter", "golang"},
+			"compiled":    true,
+			"stemcell":    "ubuntu-xenial/621.74",
+		}))
+	})
+
+	Context("a release does not have a release.MF", func() {
+		BeforeEach(func() {
+			cmd.Tile = testhelpers.MakeTileFromMap(map[string][]byte{
+				"releases/broken.tgz": MakeReleaseTarball(map[string]string{"README": "hello"}),
+			})
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.LoadReleases()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("could not read releases/broken.tgz: the release does not contain a release.MF"))
+		})
+	})
+
+	Context("a release is not a tarball", func() {
+		BeforeEach(func() {
+			cmd.Tile = testhelpers.MakeTileFromMap(map[string][]byte{
+				"releases/broken.tgz": []byte("not a tarball"),
+			})
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.LoadReleases()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("could not read releases/broken.tgz: the release is not a gzipped tarball"))
+		})
+	})
+
+	Context("the tile is not a zip file", func() {
+		BeforeEach(func() {
+			cmd.Tile = "/does/not/exist.pivotal"
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.LoadReleases()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("could not unzip /does/not/exist.pivotal"))
+		})
+	})
+})
@@ -0,0 +1,42 @@
+package releases_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/gomega"
+)
+
+// MakeReleaseTarball makes a gzipped tarball with the given files, e.g. ./release.MF
+func MakeReleaseTarball(files map[string]string) []byte {
+	buffer := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for name, contents := range files {
+		err := tarWriter.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0644,
+			Size: int64(len(contents)),
+		})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = tarWriter.Write([]byte(contents))
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	Expect(tarWriter.Close()).To(Succeed())
+	Expect(gzipWriter.Close()).To(Succeed())
+	return buffer.Bytes()
+}
+
+// MakeMetadataCmd makes a metadata command that loads the given metadata
+func MakeMetadataCmd(metadata string) *tileinspectfakes.FakeMetadataCmd {
+	metadataCmd := &tileinspectfakes.FakeMetadataCmd{}
+	metadataCmd.LoadMetadataStub = func(target interface{}) error {
+		return yaml.Unmarshal([]byte(metadata), target)
+	}
+	return metadataCmd
+}
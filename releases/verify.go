@@ -0,0 +1,170 @@
+package releases
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/metadata"
+	"github.com/pkg/errors"
+)
+
+// FindDiscrepancies compares the releases declared in the metadata with the release tarballs in the tile
+func (cmd *Config) FindDiscrepancies() ([]string, error) {
+	tileMetadata := &tileinspect.TileProperties{}
+	err := cmd.MetadataCmd.LoadMetadata(tileMetadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load tile metadata")
+	}
+
+	tile, err := metadata.OpenTile(cmd.Tile)
+	if err != nil {
+		return nil, err
+	}
+	defer tile.Close()
+
+	tarballs := make(map[string]*zip.File)
+	for _, file := range tile.File {
+		if releaseTarball.MatchString(file.Name) {
+			tarballs[path.Base(file.Name)] = file
+		}
+	}
+
+	var discrepancies []string
+	declared := make(map[string]bool)
+	// releases are the declared releases by name, nil when their tarball is missing or broken
+	releases := make(map[string]*Release)
+	jobSpecs := make(map[string]map[string]*JobSpec)
+	jobsErrs := make(map[string]error)
+	for _, expected := range tileMetadata.Releases {
+		declared[expected.File] = true
+		releases[expected.Name] = nil
+		file, ok := tarballs[expected.File]
+		if !ok {
+			discrepancies = append(discrepancies, fmt.Sprintf("release %s %s is declared in the metadata, but releases/%s is not in the tile", expected.Name, expected.Version, expected.File))
+			continue
+		}
+
+		contents, err := readReleaseTarball(file)
+		if err != nil {
+			discrepancies = append(discrepancies, err.Error())
+			continue
+		}
+		release := newRelease(path.Base(file.Name), contents.manifest)
+		releases[expected.Name] = release
+		jobSpecs[expected.Name] = contents.jobSpecs
+		if contents.jobsErr != nil {
+			jobsErrs[expected.Name] = errors.Wrapf(contents.jobsErr, "could not read the jobs of %s", file.Name)
+		}
+
+		if release.Name != expected.Name {
+			discrepancies = append(discrepancies, fmt.Sprintf("%s contains release %s, but the metadata declares release %s", file.Name, release.Name, expected.Name))
+		}
+		if release.Version != string(expected.Version) {
+			discrepancies = append(discrepancies, fmt.Sprintf("%s contains version %s, but the metadata declares version %s", file.Name, release.Version, expected.Version))
+		}
+		if tileMetadata.StemcellCriteria != nil {
+			criteria := append([]tileinspect.StemcellCriteria{*tileMetadata.StemcellCriteria}, tileMetadata.AdditionalStemcellsCriteria...)
+			discrepancies = append(discrepancies, checkCompiledStemcells(file.Name, release.Manifest, criteria)...)
+		}
+	}
+
+	var undeclared []string
+	for name, file := range tarballs {
+		if !declared[name] {
+			undeclared = append(undeclared, fmt.Sprintf("%s is in the tile, but is not declared in the metadata", file.Name))
+		}
+	}
+	sort.Strings(undeclared)
+	discrepancies = append(discrepancies, undeclared...)
+
+	// A release whose jobs fail to read is only reported once, by the first job type that uses it
+	loadJobSpecs := func(release string) (map[string]*JobSpec, error) {
+		err := jobsErrs[release]
+		delete(jobsErrs, release)
+		return jobSpecs[release], err
+	}
+	return append(discrepancies, checkJobTypes(tileMetadata.JobTypes, releases, loadJobSpecs)...), nil
+}
+
+func readReleaseTarball(file *zip.File) (*releaseContents, error) {
+	tarball, err := file.Open()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", file.Name)
+	}
+	defer tarball.Close()
+
+	contents, err := readReleaseContents(tarball)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", file.Name)
+	}
+	return contents, nil
+}
+
+// checkCompiledStemcell checks that a compiled release can be used with a stemcell line of the tile, with the same rules as the stemcell command
+func checkCompiledStemcell(line tileinspect.StemcellCriteria, stemcellOS string, stemcellVersion string) string {
+	mismatch, err := line.Mismatch(stemcellOS, stemcellVersion)
+	if err != nil {
+		return err.Error()
+	}
+	return mismatch
+}
+
+// checkCompiledStemcells checks every stemcell the packages of a compiled release were compiled against, e.g. ubuntu-xenial/621.74
+func checkCompiledStemcells(file string, manifest *ReleaseManifest, criteria []tileinspect.StemcellCriteria) []string {
+	var operatingSystems []string
+	for _, line := range criteria {
+		operatingSystems = append(operatingSystems, line.OS)
+	}
+
+	var discrepancies []string
+	checked := make(map[string]bool)
+	for _, pkg := range manifest.CompiledPackages {
+		if checked[pkg.Stemcell] {
+			continue
+		}
+		checked[pkg.Stemcell] = true
+
+		parts := strings.SplitN(pkg.Stemcell, "/", 2)
+		if len(parts) != 2 {
+			discrepancies = append(discrepancies, fmt.Sprintf("%s has package %s compiled against an invalid stemcell: %q", file, pkg.Name, pkg.Stemcell))
+			continue
+		}
+		mismatch := fmt.Sprintf("the tile requires the %s operating system", strings.Join(operatingSystems, " or "))
+		for _, line := range criteria {
+			if line.OS != parts[0] {
+				continue
+			}
+			mismatch = checkCompiledStemcell(line, parts[0], parts[1])
+			if mismatch == "" {
+				break
+			}
+		}
+		if mismatch != "" {
+			discrepancies = append(discrepancies, fmt.Sprintf("%s is compiled against stemcell %s, but %s", file, pkg.Stemcell, mismatch))
+		}
+	}
+	return discrepancies
+}
+
+// VerifyReleases prints every discrepancy between the releases in the metadata and in the tile
+func (cmd *Config) VerifyReleases(out io.Writer) error {
+	discrepancies, err := cmd.FindDiscrepancies()
+	if err != nil {
+		return err
+	}
+
+	if len(discrepancies) == 0 {
+		_, _ = fmt.Fprintln(out, "the releases in the tile match the metadata")
+		return nil
+	}
+
+	for _, discrepancy := range discrepancies {
+		_, _ = fmt.Fprintln(out, discrepancy)
+	}
+	return errors.Errorf("found %d problems with the releases", len(discrepancies))
+}
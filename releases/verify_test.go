@@ -0,0 +1,197 @@
+package releases_test
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/releases"
+	"github.com/cf-platform-eng/tileinspect/testhelpers"
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/pkg/errors"
+)
+
+func releaseManifest(name, version string) map[string]string {
+	return map[string]string{
+		"./release.MF": "name: " + name + "\nversion: " + version + "\n",
+	}
+}
+
+var _ = Describe("VerifyReleases", func() {
+	var (
+		cmd    *releases.Config
+		buffer *Buffer
+	)
+
+	BeforeEach(func() {
+		buffer = NewBuffer()
+		cmd = &releases.Config{
+			TileConfig: tileinspect.TileConfig{
+				Tile: testhelpers.MakeTileFromMap(map[string][]byte{
+					"releases/mysql-36.10.0.tgz": MakeReleaseTarball(releaseManifest("mysql", "36.10.0")),
+					"releases/routing-0.2.tgz":   MakeReleaseTarball(releaseManifest("routing", "0.2")),
+				}),
+			},
+			MetadataCmd: MakeMetadataCmd(heredoc.Doc(`
+				releases:
+				  - name: mysql
+				    file: mysql-36.10.0.tgz
+				    version: 36.10.0
+				  - name: routing
+				    file: routing-0.2.tgz
+				    version: 0.2
+				`)),
+		}
+	})
+
+	AfterEach(func() {
+		Expect(buffer.Close()).To(Succeed())
+	})
+
+	It("passes when the releases match the metadata", func() {
+		err := cmd.VerifyReleases(buffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer).To(Say("the releases in the tile match the metadata"))
+	})
+
+	Context("the releases do not match the metadata", func() {
+		BeforeEach(func() {
+			cmd.Tile = testhelpers.MakeTileFromMap(map[string][]byte{
+				"releases/mysql-36.10.0.tgz":  MakeReleaseTarball(releaseManifest("pxc", "36.11.0")),
+				"releases/extra-1.0.0.tgz":    MakeReleaseTarball(releaseManifest("extra", "1.0.0")),
+				"releases/broken-1.0.0.tgz":   []byte("not a tarball"),
+				"releases/another-2.0.0.tgz":  MakeReleaseTarball(releaseManifest("another", "2.0.0")),
+				"metadata/metadata.yml":       []byte("name: product\n"),
+				"migrations/v1/migration.js":  []byte(""),
+				"releases/nested/ignored.tgz": []byte(""),
+			})
+			cmd.MetadataCmd = MakeMetadataCmd(heredoc.Doc(`
+				releases:
+				  - name: mysql
+				    file: mysql-36.10.0.tgz
+				    version: 36.10.0
+				  - name: routing
+				    file: routing-0.2.tgz
+				    version: 0.2
+				  - name: broken
+				    file: broken-1.0.0.tgz
+				    version: 1.0.0
+				`))
+		})
+
+		It("reports every discrepancy", func() {
+			discrepancies, err := cmd.FindDiscrepancies()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(discrepancies).To(HaveLen(6))
+			Expect(discrepancies[0]).To(Equal("releases/mysql-36.10.0.tgz contains release pxc, but the metadata declares release mysql"))
+			Expect(discrepancies[1]).To(Equal("releases/mysql-36.10.0.tgz contains version 36.11.0, but the metadata declares version 36.10.0"))
+			Expect(discrepancies[2]).To(Equal("release routing 0.2 is declared in the metadata, but releases/routing-0.2.tgz is not in the tile"))
+			Expect(discrepancies[3]).To(HavePrefix("could not read releases/broken-1.0.0.tgz: the release is not a gzipped tarball"))
+			Expect(discrepancies[4]).To(Equal("releases/another-2.0.0.tgz is in the tile, but is not declared in the metadata"))
+			Expect(discrepancies[5]).To(Equal("releases/extra-1.0.0.tgz is in the tile, but is not declared in the metadata"))
+		})
+
+		It("prints the discrepancies and returns an error", func() {
+			err := cmd.VerifyReleases(buffer)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("found 6 problems with the releases"))
+			Expect(buffer).To(Say("releases/mysql-36.10.0.tgz contains release pxc"))
+			Expect(buffer).To(Say("releases/extra-1.0.0.tgz is in the tile, but is not declared in the metadata"))
+		})
+	})
+
+	Context("compiled releases", func() {
+		compiledRelease := func(name string, stemcells ...string) []byte {
+			manifest := "name: " + name + "\nversion: 1.0.0\ncompiled_packages:\n"
+			for _, stemcell := range stemcells {
+				manifest += "  - name: golang\n    stemcell: " + stemcell + "\n"
+			}
+			return MakeReleaseTarball(map[string]string{"./release.MF": manifest})
+		}
+
+		BeforeEach(func() {
+			cmd.Tile = testhelpers.MakeTileFromMap(map[string][]byte{
+				"releases/floating-1.0.0.tgz": compiledRelease("floating", "ubuntu-jammy/1.423"),
+				"releases/windows-1.0.0.tgz":  compiledRelease("windows", "windows2019/2019.71"),
+				"releases/old-1.0.0.tgz":      compiledRelease("old", "ubuntu-xenial/621.74"),
+				"releases/major-1.0.0.tgz":    compiledRelease("major", "ubuntu-jammy/2.1", "ubuntu-jammy/1.400", "ubuntu-jammy/1.400"),
+				"releases/invalid-1.0.0.tgz":  compiledRelease("invalid", "ubuntu-jammy"),
+				"releases/source-1.0.0.tgz":   MakeReleaseTarball(releaseManifest("source", "1.0.0")),
+			})
+			cmd.MetadataCmd = MakeMetadataCmd(heredoc.Doc(`
+				stemcell_criteria:
+				  os: ubuntu-jammy
+				  version: "1"
+				additional_stemcells_criteria:
+				  - os: windows2019
+				    version: "2019.70"
+				    enable_patch_security_updates: false
+				releases:
+				  - {name: floating, file: floating-1.0.0.tgz, version: 1.0.0}
+				  - {name: windows, file: windows-1.0.0.tgz, version: 1.0.0}
+				  - {name: old, file: old-1.0.0.tgz, version: 1.0.0}
+				  - {name: major, file: major-1.0.0.tgz, version: 1.0.0}
+				  - {name: invalid, file: invalid-1.0.0.tgz, version: 1.0.0}
+				  - {name: source, file: source-1.0.0.tgz, version: 1.0.0}
+				`))
+		})
+
+		It("reports the releases compiled against a stemcell the tile does not use", func() {
+			discrepancies, err := cmd.FindDiscrepancies()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(discrepancies).To(Equal([]string{
+				"releases/windows-1.0.0.tgz is compiled against stemcell windows2019/2019.71, but the tile requires exactly version 2019.70 because enable_patch_security_updates is off",
+				"releases/old-1.0.0.tgz is compiled against stemcell ubuntu-xenial/621.74, but the tile requires the ubuntu-jammy or windows2019 operating system",
+				"releases/major-1.0.0.tgz is compiled against stemcell ubuntu-jammy/2.1, but the tile requires major version 1",
+				`releases/invalid-1.0.0.tgz has package golang compiled against an invalid stemcell: "ubuntu-jammy"`,
+			}))
+		})
+
+		Context("the stemcell line allows later patch versions", func() {
+			BeforeEach(func() {
+				cmd.MetadataCmd = MakeMetadataCmd(heredoc.Doc(`
+					stemcell_criteria:
+					  os: windows2019
+					  version: "2019.70"
+					releases:
+					  - {name: windows, file: windows-1.0.0.tgz, version: 1.0.0}
+					`))
+				cmd.Tile = testhelpers.MakeTileFromMap(map[string][]byte{
+					"releases/windows-1.0.0.tgz": compiledRelease("windows", "windows2019/2019.71"),
+				})
+			})
+
+			It("accepts a release compiled against a later version", func() {
+				discrepancies, err := cmd.FindDiscrepancies()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(discrepancies).To(BeEmpty())
+			})
+
+			It("reports a release compiled against an earlier version", func() {
+				cmd.Tile = testhelpers.MakeTileFromMap(map[string][]byte{
+					"releases/windows-1.0.0.tgz": compiledRelease("windows", "windows2019/2019.60"),
+				})
+				discrepancies, err := cmd.FindDiscrepancies()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(discrepancies).To(Equal([]string{
+					"releases/windows-1.0.0.tgz is compiled against stemcell windows2019/2019.60, but the tile requires version 2019.70 or later",
+				}))
+			})
+		})
+	})
+
+	Context("the metadata fails to load", func() {
+		BeforeEach(func() {
+			metadataCmd := &tileinspectfakes.FakeMetadataCmd{}
+			metadataCmd.LoadMetadataReturns(errors.New("metadata-load-error"))
+			cmd.MetadataCmd = metadataCmd
+		})
+
+		It("returns an error", func() {
+			err := cmd.VerifyReleases(buffer)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to load tile metadata: metadata-load-error"))
+		})
+	})
+})
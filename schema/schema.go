@@ -0,0 +1,434 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/makeconfig"
+	"github.com/cf-platform-eng/tileinspect/metadata"
+	"github.com/pkg/errors"
+)
+
+const (
+	SchemaVersion          = "http://json-schema.org/draft-07/schema#"
+	variablePlaceholderRef = "^\\(\\(.+\\)\\)$"
+)
+
+type Config struct {
+	tileinspect.TileConfig
+	MetadataCmd tileinspect.MetadataCmd
+}
+
+// Schema is a JSON Schema document, built from plain maps so it can be encoded as is
+type Schema map[string]interface{}
+
+type builder struct {
+	inputs map[string]tileinspect.PropertyInput
+	// keys holds the schema of every property key that can appear in product-properties
+	keys Schema
+	// conditions holds the if/then/else rules for selector options
+	conditions []Schema
+	required   []string
+}
+
+// isRequired mirrors the rule used by check-config
+func isRequired(property tileinspect.TileProperty) bool {
+	return property.Configurable && !property.Optional && property.Default == nil && property.Type != "dropdown_select"
+}
+
+func withConstraints(schema Schema, property tileinspect.TileProperty) Schema {
+	constraints := property.Constraints
+	if constraints == nil {
+		return schema
+	}
+
+	if constraints.Min != nil {
+		schema["minimum"] = *constraints.Min
+	}
+	if constraints.Max != nil {
+		schema["maximum"] = *constraints.Max
+	}
+	if constraints.MinLength != nil {
+		schema["minLength"] = *constraints.MinLength
+	}
+	if constraints.MaxLength != nil {
+		schema["maxLength"] = *constraints.MaxLength
+	}
+	if len(constraints.MustMatchRegex) == 1 {
+		schema["pattern"] = constraints.MustMatchRegex[0]
+	} else if len(constraints.MustMatchRegex) > 1 {
+		var patterns []interface{}
+		for _, pattern := range constraints.MustMatchRegex {
+			patterns = append(patterns, Schema{"pattern": pattern})
+		}
+		schema["allOf"] = patterns
+	}
+	return schema
+}
+
+func credentialSchema(propertyType string) Schema {
+	fields := Schema{}
+	var required []interface{}
+	for _, field := range makeconfig.CredentialFields[propertyType] {
+		fields[field] = Schema{"type": "string"}
+		required = append(required, field)
+	}
+
+	return Schema{
+		"anyOf": []interface{}{
+			Schema{
+				"type":       "object",
+				"properties": fields,
+				"required":   required,
+			},
+			Schema{
+				"type":    "string",
+				"pattern": variablePlaceholderRef,
+			},
+		},
+	}
+}
+
+func collectionItemSchema(property tileinspect.TileProperty) Schema {
+	fields := Schema{}
+	var required []interface{}
+	for _, child := range property.PropertyBlueprints {
+		if !child.Configurable {
+			fields[child.Name] = false
+			continue
+		}
+		fields[child.Name] = ValueSchema(child)
+		if !child.Optional && child.Default == nil {
+			required = append(required, child.Name)
+		}
+	}
+
+	item := Schema{
+		"type":       "object",
+		"properties": fields,
+	}
+	if len(required) > 0 {
+		item["required"] = required
+	}
+	return item
+}
+
+// hasRequiredItemFields mirrors check-config, which reports an empty collection that is not optional when its items have required fields
+func hasRequiredItemFields(property tileinspect.TileProperty) bool {
+	if property.Optional {
+		return false
+	}
+	for _, child := range property.PropertyBlueprints {
+		if child.Configurable && !child.Optional && child.Default == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ValueSchema returns the schema for the value of a property
+func ValueSchema(property tileinspect.TileProperty) Schema {
+	switch property.Type {
+	case "integer", "port":
+		return withConstraints(Schema{"type": "integer"}, property)
+	case "boolean":
+		return Schema{"type": "boolean"}
+	case "secret":
+		secret := Schema{"type": "string"}
+		if isRequired(property) {
+			secret["minLength"] = 1
+		}
+		return Schema{
+			"type":                 "object",
+			"properties":           Schema{"secret": secret},
+			"required":             []interface{}{"secret"},
+			"additionalProperties": false,
+		}
+	case "dropdown_select":
+		var names []interface{}
+		for _, option := range property.Options {
+			names = append(names, option.Name)
+		}
+		return Schema{"enum": names}
+	case "multi_select_options":
+		var names []interface{}
+		for _, option := range property.Options {
+			names = append(names, option.Name)
+		}
+		return Schema{"type": "array", "items": Schema{"enum": names}}
+	case "selector":
+		var values []interface{}
+		for _, option := range property.ChildProperties {
+			values = append(values, option.SelectValue)
+		}
+		return Schema{"type": "string", "enum": values}
+	case "collection":
+		collection := Schema{"type": "array", "items": collectionItemSchema(property)}
+		if hasRequiredItemFields(property) {
+			collection["minItems"] = 1
+		}
+		return collection
+	case "rsa_cert_credentials", "rsa_pkey_credentials", "salted_credentials", "simple_credentials":
+		return credentialSchema(property.Type)
+	case "string", "text", "network_address", "network_address_list", "ip_ranges", "domain", "wildcard_domain",
+		"email", "http_url", "ldap_url", "string_list", "uuid", "vm_type_dropdown", "disk_type_dropdown":
+		return withConstraints(Schema{"type": "string"}, property)
+	}
+
+	return Schema{}
+}
+
+func (b *builder) propertySchema(propertyKey string, property tileinspect.TileProperty) interface{} {
+	if !property.Configurable {
+		return false
+	}
+
+	schema := Schema{
+		"type": "object",
+		"properties": Schema{
+			"type":     Schema{"type": "string"},
+			"value":    ValueSchema(property),
+			"required": Schema{"type": "boolean"},
+		},
+		"required": []interface{}{"value"},
+	}
+
+	input := b.inputs[propertyKey]
+	if input.Label != "" {
+		schema["title"] = input.Label
+	}
+	if input.Description != "" {
+		schema["description"] = input.Description
+	}
+	if property.Default != nil {
+		schema["default"] = Schema{"value": property.Default}
+	}
+	return schema
+}
+
+// isSelected builds the condition for an option of a selector being selected, the same way check-config decides it
+func isSelected(propertyKey string, property tileinspect.TileProperty, option tileinspect.TileProperties) Schema {
+	hasValue := Schema{
+		"required": []interface{}{propertyKey},
+		"properties": Schema{
+			propertyKey: Schema{
+				"properties": Schema{"value": Schema{"const": option.SelectValue}},
+			},
+		},
+	}
+
+	if property.Default == option.SelectValue {
+		return Schema{
+			"anyOf": []interface{}{
+				hasValue,
+				Schema{"not": Schema{"required": []interface{}{propertyKey}}},
+			},
+		}
+	}
+	return hasValue
+}
+
+func (b *builder) addProperties(propertyPrefix string, blueprints []tileinspect.TileProperty, selected []interface{}) []string {
+	var required []string
+	for _, property := range blueprints {
+		propertyKey := propertyPrefix + "." + property.Name
+		b.keys[propertyKey] = b.propertySchema(propertyKey, property)
+
+		if isRequired(property) {
+			required = append(required, propertyKey)
+		}
+
+		if property.Type != "selector" {
+			continue
+		}
+
+		for _, option := range property.ChildProperties {
+			condition := isSelected(propertyKey, property, option)
+			optionSelected := append(append([]interface{}{}, selected...), condition)
+
+			childPrefix := propertyKey + "." + option.Name
+			childRequired := b.addProperties(childPrefix, option.PropertyBlueprints, optionSelected)
+
+			forbidden := Schema{}
+			for _, child := range option.PropertyBlueprints {
+				forbidden[childPrefix+"."+child.Name] = false
+			}
+
+			rule := Schema{
+				"if":   Schema{"allOf": optionSelected},
+				"else": Schema{"properties": forbidden},
+			}
+			if len(childRequired) > 0 {
+				var requiredKeys []interface{}
+				for _, key := range childRequired {
+					requiredKeys = append(requiredKeys, key)
+				}
+				rule["then"] = Schema{"required": requiredKeys}
+			}
+			b.conditions = append(b.conditions, rule)
+		}
+	}
+	return required
+}
+
+func networkPropertiesSchema() Schema {
+	name := Schema{
+		"type":       "object",
+		"properties": Schema{"name": Schema{"type": "string"}},
+		"required":   []interface{}{"name"},
+	}
+	return Schema{
+		"type": "object",
+		"properties": Schema{
+			"network":                     name,
+			"service_network":             name,
+			"other_availability_zones":    Schema{"type": "array", "items": name},
+			"singleton_availability_zone": name,
+		},
+		"required": []interface{}{"network", "other_availability_zones", "singleton_availability_zone"},
+	}
+}
+
+func resourceConfigSchema(jobTypes []tileinspect.JobType) Schema {
+	automaticOr := func(schema Schema) Schema {
+		return Schema{"anyOf": []interface{}{Schema{"const": makeconfig.Automatic}, schema}}
+	}
+
+	jobs := Schema{}
+	for _, jobType := range jobTypes {
+		instances := Schema{"type": "integer", "minimum": 0}
+		if jobType.InstanceDefinition != nil {
+			instances = withConstraints(instances, *jobType.InstanceDefinition)
+		}
+
+		jobs[jobType.Name] = Schema{
+			"type": "object",
+			"properties": Schema{
+				"instances": automaticOr(instances),
+				"persistent_disk": Schema{
+					"type":       "object",
+					"properties": Schema{"size_mb": Schema{"type": "string"}},
+				},
+				"instance_type": Schema{
+					"type":       "object",
+					"properties": Schema{"id": Schema{"type": "string"}},
+				},
+			},
+		}
+	}
+
+	return Schema{
+		"type":                 "object",
+		"properties":           jobs,
+		"additionalProperties": false,
+	}
+}
+
+func errandConfigSchema(tileProperties *tileinspect.TileProperties) Schema {
+	state := Schema{
+		"anyOf": []interface{}{
+			Schema{"type": "boolean"},
+			Schema{"enum": []interface{}{makeconfig.DefaultErrandState, "when-changed"}},
+		},
+	}
+
+	states := make(map[string]Schema)
+	for _, errand := range tileProperties.PostDeployErrands {
+		if states[errand.Name] == nil {
+			states[errand.Name] = Schema{}
+		}
+		states[errand.Name]["post-deploy-state"] = state
+	}
+	for _, errand := range tileProperties.PreDeleteErrands {
+		if states[errand.Name] == nil {
+			states[errand.Name] = Schema{}
+		}
+		states[errand.Name]["pre-delete-state"] = state
+	}
+
+	errands := Schema{}
+	for name, properties := range states {
+		errands[name] = Schema{"type": "object", "properties": properties}
+	}
+
+	return Schema{
+		"type":                 "object",
+		"properties":           errands,
+		"additionalProperties": false,
+	}
+}
+
+func MakeSchema(tileProperties *tileinspect.TileProperties) Schema {
+	b := &builder{
+		inputs: tileProperties.PropertyInputs(),
+		keys:   Schema{},
+	}
+
+	b.required = append(b.required, b.addProperties(".properties", tileProperties.PropertyBlueprints, nil)...)
+	for _, jobType := range tileProperties.JobTypes {
+		b.required = append(b.required, b.addProperties("."+jobType.Name, jobType.PropertyBlueprints, nil)...)
+	}
+
+	productProperties := Schema{
+		"type":                 "object",
+		"properties":           b.keys,
+		"additionalProperties": false,
+	}
+	if len(b.required) > 0 {
+		var required []interface{}
+		for _, key := range b.required {
+			required = append(required, key)
+		}
+		productProperties["required"] = required
+	}
+	if len(b.conditions) > 0 {
+		var conditions []interface{}
+		for _, condition := range b.conditions {
+			conditions = append(conditions, condition)
+		}
+		productProperties["allOf"] = conditions
+	}
+
+	return Schema{
+		"$schema": SchemaVersion,
+		"title":   fmt.Sprintf("Config file for %s", tileProperties.Name),
+		"type":    "object",
+		"properties": Schema{
+			"product-name":       Schema{"const": tileProperties.Name},
+			"product-properties": productProperties,
+			"network-properties": networkPropertiesSchema(),
+			"resource-config":    resourceConfigSchema(tileProperties.JobTypes),
+			"errand-config":      errandConfigSchema(tileProperties),
+		},
+		"required": []interface{}{"product-properties"},
+	}
+}
+
+func (cmd *Config) WriteSchema(out io.Writer) error {
+	tileProperties := &tileinspect.TileProperties{}
+	err := cmd.MetadataCmd.LoadMetadata(tileProperties)
+	if err != nil {
+		return errors.Wrap(err, "failed to load tile metadata")
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(MakeSchema(tileProperties))
+	if err != nil { // !branch-not-tested No good way to force this
+		return errors.Wrap(err, "failed to encode schema JSON")
+	}
+
+	return nil
+}
+
+func (cmd *Config) Execute(args []string) error {
+	cmd.MetadataCmd = &metadata.Config{
+		TileConfig: tileinspect.TileConfig{
+			Tile: cmd.Tile,
+		},
+	}
+	return cmd.WriteSchema(os.Stdout)
+}
@@ -0,0 +1,261 @@
+package schema_test
+
+import (
+	"encoding/json"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/schema"
+	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("WriteSchema", func() {
+	var (
+		buffer      *Buffer
+		cmd         *schema.Config
+		metadataCmd *tileinspectfakes.FakeMetadataCmd
+	)
+
+	BeforeEach(func() {
+		buffer = NewBuffer()
+		metadataCmd = &tileinspectfakes.FakeMetadataCmd{}
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := yaml.Unmarshal([]byte(heredoc.Doc(`
+			---
+			name: product
+			property_blueprints:
+			  - name: hostname
+			    type: string
+			    configurable: true
+			    constraints:
+			      must_match_regex: '^[a-z.]+$'
+			  - name: max_conns
+			    type: integer
+			    configurable: true
+			    default: 10
+			    constraints:
+			      min: 1
+			      max: 100
+			  - name: internal
+			    type: string
+			  - name: admin-password
+			    type: secret
+			    configurable: true
+			  - name: admin
+			    type: simple_credentials
+			    configurable: true
+			    optional: true
+			  - name: flavor
+			    type: dropdown_select
+			    configurable: true
+			    options:
+			      - name: vanilla
+			      - name: chocolate
+			  - name: continent
+			    type: selector
+			    configurable: true
+			    default: North America
+			    option_templates:
+			      - name: north-america
+			        select_value: North America
+			        property_blueprints:
+			          - name: city
+			            type: string
+			            configurable: true
+			      - name: australia
+			        select_value: Australia
+			        property_blueprints:
+			          - name: region
+			            type: string
+			            configurable: true
+			            default: west
+			job_types:
+			  - name: server
+			    instance_definition:
+			      name: instances
+			      type: integer
+			      configurable: true
+			      default: 1
+			      constraints:
+			        min: 1
+			    property_blueprints:
+			      - name: api-key
+			        type: secret
+			        configurable: true
+			post_deploy_errands:
+			  - name: smoke-tests
+			form_types:
+			  - name: config
+			    property_inputs:
+			      - reference: .properties.hostname
+			        label: Hostname
+			        description: The hostname of the server
+			`)), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+
+		cmd = &schema.Config{
+			MetadataCmd: metadataCmd,
+		}
+	})
+
+	AfterEach(func() {
+		err := buffer.Close()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	writeSchema := func() map[string]interface{} {
+		err := cmd.WriteSchema(buffer)
+		Expect(err).ToNot(HaveOccurred())
+
+		var document map[string]interface{}
+		err = json.Unmarshal(buffer.Contents(), &document)
+		Expect(err).ToNot(HaveOccurred())
+		return document
+	}
+
+	productProperties := func(document map[string]interface{}) map[string]interface{} {
+		return document["properties"].(map[string]interface{})["product-properties"].(map[string]interface{})
+	}
+
+	propertyValue := func(document map[string]interface{}, propertyKey string) map[string]interface{} {
+		keys := productProperties(document)["properties"].(map[string]interface{})
+		return keys[propertyKey].(map[string]interface{})["properties"].(map[string]interface{})["value"].(map[string]interface{})
+	}
+
+	It("describes the config file", func() {
+		document := writeSchema()
+		Expect(document["$schema"]).To(Equal(schema.SchemaVersion))
+		Expect(document["required"]).To(ConsistOf("product-properties"))
+		Expect(document["properties"]).To(HaveKeyWithValue("product-name", map[string]interface{}{"const": "product"}))
+	})
+
+	It("describes each property", func() {
+		document := writeSchema()
+		keys := productProperties(document)["properties"].(map[string]interface{})
+		Expect(keys).To(HaveLen(10))
+		Expect(keys[".properties.internal"]).To(Equal(false))
+
+		hostname := keys[".properties.hostname"].(map[string]interface{})
+		Expect(hostname["title"]).To(Equal("Hostname"))
+		Expect(hostname["description"]).To(Equal("The hostname of the server"))
+		Expect(propertyValue(document, ".properties.hostname")).To(Equal(map[string]interface{}{"type": "string", "pattern": "^[a-z.]+$"}))
+
+		Expect(propertyValue(document, ".properties.max_conns")).To(Equal(map[string]interface{}{"type": "integer", "minimum": 1.0, "maximum": 100.0}))
+		Expect(propertyValue(document, ".properties.flavor")).To(Equal(map[string]interface{}{"enum": []interface{}{"vanilla", "chocolate"}}))
+		Expect(propertyValue(document, ".properties.continent")).To(Equal(map[string]interface{}{"type": "string", "enum": []interface{}{"North America", "Australia"}}))
+		Expect(propertyValue(document, ".server.api-key")["required"]).To(ConsistOf("secret"))
+		Expect(propertyValue(document, ".properties.admin")["anyOf"]).To(ContainElement(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"identity": map[string]interface{}{"type": "string"},
+				"password": map[string]interface{}{"type": "string"},
+			},
+			"required": []interface{}{"identity", "password"},
+		}))
+	})
+
+	It("requires the same properties as check-config", func() {
+		document := writeSchema()
+		Expect(productProperties(document)["required"]).To(ConsistOf(
+			".properties.hostname",
+			".properties.admin-password",
+			".server.api-key",
+		))
+	})
+
+	It("makes the properties of selector options conditional", func() {
+		document := writeSchema()
+		conditions := productProperties(document)["allOf"].([]interface{})
+		Expect(conditions).To(HaveLen(2))
+
+		northAmerica := conditions[0].(map[string]interface{})
+		Expect(northAmerica["then"]).To(Equal(map[string]interface{}{
+			"required": []interface{}{".properties.continent.north-america.city"},
+		}))
+		Expect(northAmerica["else"]).To(Equal(map[string]interface{}{
+			"properties": map[string]interface{}{".properties.continent.north-america.city": false},
+		}))
+		// North America is the default, so it is also selected when the selector is missing
+		Expect(northAmerica["if"].(map[string]interface{})["allOf"].([]interface{})[0]).To(HaveKey("anyOf"))
+
+		australia := conditions[1].(map[string]interface{})
+		Expect(australia).ToNot(HaveKey("then"))
+		Expect(australia["if"].(map[string]interface{})["allOf"]).To(ConsistOf(map[string]interface{}{
+			"required": []interface{}{".properties.continent"},
+			"properties": map[string]interface{}{
+				".properties.continent": map[string]interface{}{
+					"properties": map[string]interface{}{"value": map[string]interface{}{"const": "Australia"}},
+				},
+			},
+		}))
+	})
+
+	It("describes the jobs and errands", func() {
+		document := writeSchema()
+		sections := document["properties"].(map[string]interface{})
+
+		jobs := sections["resource-config"].(map[string]interface{})["properties"].(map[string]interface{})
+		Expect(jobs).To(HaveKey("server"))
+		instances := jobs["server"].(map[string]interface{})["properties"].(map[string]interface{})["instances"]
+		Expect(instances).To(Equal(map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"const": "automatic"},
+				map[string]interface{}{"type": "integer", "minimum": 1.0},
+			},
+		}))
+
+		errands := sections["errand-config"].(map[string]interface{})["properties"].(map[string]interface{})
+		Expect(errands).To(HaveKey("smoke-tests"))
+		Expect(errands["smoke-tests"].(map[string]interface{})["properties"]).To(HaveKey("post-deploy-state"))
+		Expect(errands["smoke-tests"].(map[string]interface{})["properties"]).ToNot(HaveKey("pre-delete-state"))
+	})
+
+	Context("metadata fails to load", func() {
+		BeforeEach(func() {
+			metadataCmd.LoadMetadataReturns(errors.New("metadata-error"))
+		})
+
+		It("returns an error", func() {
+			err := cmd.WriteSchema(buffer)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to load tile metadata: metadata-error"))
+		})
+	})
+})
+
+var _ = Describe("ValueSchema", func() {
+	var collection tileinspect.TileProperty
+
+	BeforeEach(func() {
+		collection = tileinspect.TileProperty{
+			Name:         "users",
+			Type:         "collection",
+			Configurable: true,
+			PropertyBlueprints: []tileinspect.TileProperty{
+				{Name: "name", Type: "string", Configurable: true},
+				{Name: "admin", Type: "boolean", Configurable: true, Default: false},
+			},
+		}
+	})
+
+	It("requires an item in a collection that check-config requires", func() {
+		Expect(schema.ValueSchema(collection)).To(HaveKeyWithValue("minItems", 1))
+	})
+
+	It("allows an empty optional collection", func() {
+		collection.Optional = true
+		Expect(schema.ValueSchema(collection)).ToNot(HaveKey("minItems"))
+	})
+
+	It("allows an empty collection without required fields", func() {
+		collection.PropertyBlueprints[0].Optional = true
+		Expect(schema.ValueSchema(collection)).ToNot(HaveKey("minItems"))
+	})
+})
@@ -3,6 +3,7 @@ package stemcell
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -14,24 +15,73 @@ import (
 
 type Config struct {
 	tileinspect.TileConfig
-	MetadataCmd tileinspect.MetadataCmd
+	OS           string `long:"os" description:"only print the stemcell lines for this operating system, as a list"`
+	CheckOS      string `long:"check-os" description:"check whether a stemcell with this operating system satisfies the tile, requires --check-version"`
+	CheckVersion string `long:"check-version" description:"check whether a stemcell with this version satisfies the tile, requires --check-os"`
+	MetadataCmd  tileinspect.MetadataCmd
 }
 
-func (cmd *Config) WriteStemcell(out io.Writer) error {
+// StemcellLine is the stemcell criteria of one stemcell line, as printed by the stemcell command
+type StemcellLine struct {
+	tileinspect.StemcellCriteria
+	Floating                    bool           `json:"floating"`
+	AdditionalStemcellsCriteria []StemcellLine `json:"additional_stemcells_criteria,omitempty"`
+}
+
+// MarshalJSON writes the keys of the stemcell criteria as written in the metadata, with floating and the additional lines
+func (line StemcellLine) MarshalJSON() ([]byte, error) {
+	fields := line.StemcellCriteria.Fields()
+	fields["floating"] = line.Floating
+	if len(line.AdditionalStemcellsCriteria) > 0 {
+		fields["additional_stemcells_criteria"] = line.AdditionalStemcellsCriteria
+	}
+	return json.Marshal(fields)
+}
+
+func newStemcellLine(criteria tileinspect.StemcellCriteria) StemcellLine {
+	return StemcellLine{
+		StemcellCriteria: criteria,
+		Floating:         criteria.IsFloating(),
+	}
+}
+
+func (cmd *Config) loadStemcellCriteria() ([]tileinspect.StemcellCriteria, error) {
 	tileMetadata := &tileinspect.TileProperties{}
 	err := cmd.MetadataCmd.LoadMetadata(&tileMetadata)
 	if err != nil {
-		return Wrap(err, "failed to load tile metadata")
+		return nil, Wrap(err, "failed to load tile metadata")
 	}
 
-	version, ok := tileMetadata.StemcellCriteria["version"].(string)
-	if !ok {
-		return errors.New("could not convert stemcell criteria version to string")
+	if tileMetadata.StemcellCriteria == nil {
+		return nil, errors.New("the tile does not have stemcell criteria")
+	}
+	return append([]tileinspect.StemcellCriteria{*tileMetadata.StemcellCriteria}, tileMetadata.AdditionalStemcellsCriteria...), nil
+}
+
+func (cmd *Config) WriteStemcell(out io.Writer) error {
+	criteria, err := cmd.loadStemcellCriteria()
+	if err != nil {
+		return err
+	}
+
+	var output interface{}
+	if cmd.OS != "" {
+		matching := []StemcellLine{}
+		for _, line := range criteria {
+			if line.OS == cmd.OS {
+				matching = append(matching, newStemcellLine(line))
+			}
+		}
+		output = matching
+	} else {
+		primary := newStemcellLine(criteria[0])
+		for _, line := range criteria[1:] {
+			primary.AdditionalStemcellsCriteria = append(primary.AdditionalStemcellsCriteria, newStemcellLine(line))
+		}
+		output = primary
 	}
-	fixed := strings.Contains(version, ".")
 
-	tileMetadata.StemcellCriteria["floating"] = !fixed
-	err = json.NewEncoder(out).Encode(tileMetadata.StemcellCriteria)
+	err = json.NewEncoder(out).Encode(output)
 	if err != nil { // !branch-not-tested No good way to force this
 		return Wrap(err, "failed to encode stemcell JSON")
 	}
@@ -39,11 +89,61 @@ func (cmd *Config) WriteStemcell(out io.Writer) error {
 	return nil
 }
 
+// checkCriteria checks the stemcell against one stemcell line of the tile
+func checkCriteria(criteria tileinspect.StemcellCriteria, stemcellOS string, stemcellVersion string) error {
+	mismatch, err := criteria.Mismatch(stemcellOS, stemcellVersion)
+	if err != nil {
+		return err
+	}
+	if mismatch != "" {
+		return Errorf("stemcell %s %s is incompatible: %s", stemcellOS, stemcellVersion, mismatch)
+	}
+	return nil
+}
+
+// CheckStemcell checks the stemcell against each stemcell line of the tile, it only needs to satisfy one of them
+func (cmd *Config) CheckStemcell(out io.Writer) error {
+	criteria, err := cmd.loadStemcellCriteria()
+	if err != nil {
+		return err
+	}
+
+	var operatingSystems []string
+	var mismatch error
+	for _, line := range criteria {
+		operatingSystems = append(operatingSystems, line.OS)
+		if line.OS != cmd.CheckOS {
+			continue
+		}
+
+		mismatch = checkCriteria(line, cmd.CheckOS, cmd.CheckVersion)
+		if mismatch == nil {
+			_, _ = fmt.Fprintf(out, "stemcell %s %s satisfies the stemcell criteria (%s %s)\n", cmd.CheckOS, cmd.CheckVersion, line.OS, line.Version)
+			if line.RequiresCPI {
+				_, _ = fmt.Fprintln(out, "the tile also requires a stemcell with a CPI, make sure the stemcell is built for your IaaS")
+			}
+			return nil
+		}
+	}
+
+	if mismatch != nil {
+		return mismatch
+	}
+	return Errorf("stemcell %s %s is incompatible: the tile requires the %s operating system", cmd.CheckOS, cmd.CheckVersion, strings.Join(operatingSystems, " or "))
+}
+
 func (cmd *Config) Execute(args []string) error {
 	cmd.MetadataCmd = &metadata.Config{
 		TileConfig: tileinspect.TileConfig{
 			Tile: cmd.Tile,
 		},
 	}
+
+	if cmd.CheckOS != "" || cmd.CheckVersion != "" {
+		if cmd.CheckOS == "" || cmd.CheckVersion == "" {
+			return errors.New("--check-os and --check-version need to be used together")
+		}
+		return cmd.CheckStemcell(os.Stdout)
+	}
 	return cmd.WriteStemcell(os.Stdout)
 }
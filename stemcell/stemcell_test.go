@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/cf-platform-eng/tileinspect"
 	"github.com/cf-platform-eng/tileinspect/stemcell"
 	"github.com/cf-platform-eng/tileinspect/tileinspectfakes"
+	"github.com/ghodss/yaml"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gbytes"
@@ -94,6 +96,121 @@ var _ = Describe("WriteStemcell", func() {
 			})
 		})
 
+		Context("Numeric stemcell version", func() {
+			BeforeEach(func() {
+				metadataCmd.LoadMetadataStub = func(target interface{}) error {
+					err := yaml.Unmarshal([]byte(heredoc.Doc(`
+					stemcell_criteria:
+					  os: ubuntu-xenial
+					  version: 621
+					`)), &target)
+					Expect(err).ToNot(HaveOccurred())
+					return nil
+				}
+			})
+
+			It("returns the version as a string", func() {
+				config := stemcell.Config{
+					MetadataCmd: metadataCmd,
+				}
+				err := config.WriteStemcell(buffer)
+				Expect(err).ToNot(HaveOccurred())
+
+				var stemcellCriteria map[string]interface{}
+				err = json.Unmarshal(buffer.Contents(), &stemcellCriteria)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stemcellCriteria["version"]).To(Equal("621"))
+				Expect(stemcellCriteria["floating"]).To(Equal(true))
+			})
+		})
+
+		Context("No stemcell criteria", func() {
+			BeforeEach(func() {
+				metadataCmd.LoadMetadataStub = func(target interface{}) error {
+					err := json.Unmarshal([]byte(`{"name": "product"}`), &target)
+					Expect(err).ToNot(HaveOccurred())
+					return nil
+				}
+			})
+
+			It("returns an error", func() {
+				config := stemcell.Config{
+					MetadataCmd: metadataCmd,
+				}
+				err := config.WriteStemcell(buffer)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("the tile does not have stemcell criteria"))
+			})
+		})
+
+		Context("Additional stemcell lines", func() {
+			BeforeEach(func() {
+				metadataCmd.LoadMetadataStub = func(target interface{}) error {
+					err := json.Unmarshal([]byte(heredoc.Doc(`{
+					  "stemcell_criteria": {
+						"os": "ubuntu-jammy",
+						"version": "1.400"
+					  },
+					  "additional_stemcells_criteria": [
+						{"os": "windows2019", "version": "2019", "requires_cpi": true, "internal_notes": "kept as is"},
+						{"os": "ubuntu-xenial", "version": "621.90"}
+					  ]
+					}`)), &target)
+					Expect(err).ToNot(HaveOccurred())
+					return nil
+				}
+			})
+
+			It("returns every stemcell line", func() {
+				config := stemcell.Config{
+					MetadataCmd: metadataCmd,
+				}
+				err := config.WriteStemcell(buffer)
+				Expect(err).ToNot(HaveOccurred())
+
+				var stemcellCriteria map[string]interface{}
+				err = json.Unmarshal(buffer.Contents(), &stemcellCriteria)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(stemcellCriteria["os"]).To(Equal("ubuntu-jammy"))
+				Expect(stemcellCriteria["floating"]).To(Equal(false))
+				Expect(stemcellCriteria["additional_stemcells_criteria"]).To(Equal([]interface{}{
+					map[string]interface{}{"os": "windows2019", "version": "2019", "requires_cpi": true, "internal_notes": "kept as is", "floating": true},
+					map[string]interface{}{"os": "ubuntu-xenial", "version": "621.90", "floating": false},
+				}))
+			})
+
+			It("filters the stemcell lines by operating system", func() {
+				config := stemcell.Config{
+					OS:          "windows2019",
+					MetadataCmd: metadataCmd,
+				}
+				err := config.WriteStemcell(buffer)
+				Expect(err).ToNot(HaveOccurred())
+
+				var stemcellLines []interface{}
+				err = json.Unmarshal(buffer.Contents(), &stemcellLines)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stemcellLines).To(Equal([]interface{}{
+					map[string]interface{}{"os": "windows2019", "version": "2019", "requires_cpi": true, "internal_notes": "kept as is", "floating": true},
+				}))
+			})
+
+			It("checks a stemcell against the line for its operating system", func() {
+				config := stemcell.Config{
+					CheckOS:      "ubuntu-xenial",
+					CheckVersion: "621.95",
+					MetadataCmd:  metadataCmd,
+				}
+				Expect(config.CheckStemcell(buffer)).To(Succeed())
+
+				config.CheckOS = "centos"
+				err := config.CheckStemcell(buffer)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("stemcell centos 621.95 is incompatible: the tile requires the ubuntu-jammy or windows2019 or ubuntu-xenial operating system"))
+			})
+		})
+
 		Context("Failed to get metadata", func() {
 			BeforeEach(func() {
 				metadataCmd.LoadMetadataReturns(errors.New("write metadata error"))
@@ -110,3 +227,139 @@ var _ = Describe("WriteStemcell", func() {
 		})
 	})
 })
+
+var _ = Describe("CheckStemcell", func() {
+	var (
+		buffer      *Buffer
+		config      *stemcell.Config
+		metadataCmd *tileinspectfakes.FakeMetadataCmd
+	)
+
+	setCriteria := func(criteria string) {
+		metadataCmd.LoadMetadataStub = func(target interface{}) error {
+			err := json.Unmarshal([]byte(`{"stemcell_criteria": `+criteria+`}`), &target)
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		}
+	}
+
+	check := func(os string, version string) error {
+		config.CheckOS = os
+		config.CheckVersion = version
+		return config.CheckStemcell(buffer)
+	}
+
+	BeforeEach(func() {
+		buffer = NewBuffer()
+		metadataCmd = &tileinspectfakes.FakeMetadataCmd{}
+		config = &stemcell.Config{
+			MetadataCmd: metadataCmd,
+		}
+	})
+
+	AfterEach(func() {
+		err := buffer.Close()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("Floating stemcell version", func() {
+		BeforeEach(func() {
+			setCriteria(`{"os": "ubuntu-jammy", "version": "1"}`)
+		})
+
+		It("accepts any version of the same major", func() {
+			Expect(check("ubuntu-jammy", "1.423")).To(Succeed())
+			Expect(string(buffer.Contents())).To(Equal("stemcell ubuntu-jammy 1.423 satisfies the stemcell criteria (ubuntu-jammy 1)\n"))
+		})
+
+		It("rejects another major", func() {
+			err := check("ubuntu-jammy", "2.1")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("stemcell ubuntu-jammy 2.1 is incompatible: the tile requires major version 1"))
+		})
+
+		It("rejects another operating system", func() {
+			err := check("ubuntu-xenial", "1.423")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("stemcell ubuntu-xenial 1.423 is incompatible: the tile requires the ubuntu-jammy operating system"))
+		})
+	})
+
+	Context("Fixed stemcell version with patch security updates", func() {
+		BeforeEach(func() {
+			setCriteria(`{"os": "ubuntu-jammy", "version": "1.400"}`)
+		})
+
+		It("accepts the same or a later version of the same major", func() {
+			Expect(check("ubuntu-jammy", "1.400")).To(Succeed())
+			Expect(check("ubuntu-jammy", "1.423")).To(Succeed())
+		})
+
+		It("rejects an earlier version", func() {
+			err := check("ubuntu-jammy", "1.399")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("stemcell ubuntu-jammy 1.399 is incompatible: the tile requires version 1.400 or later"))
+		})
+	})
+
+	Context("Fixed stemcell version without patch security updates", func() {
+		BeforeEach(func() {
+			setCriteria(`{"os": "ubuntu-jammy", "version": "1.400", "enable_patch_security_updates": false, "requires_cpi": true}`)
+		})
+
+		It("accepts only that version", func() {
+			Expect(check("ubuntu-jammy", "1.400")).To(Succeed())
+			Expect(string(buffer.Contents())).To(ContainSubstring("requires a stemcell with a CPI"))
+
+			err := check("ubuntu-jammy", "1.423")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("stemcell ubuntu-jammy 1.423 is incompatible: the tile requires exactly version 1.400 because enable_patch_security_updates is off"))
+		})
+	})
+
+	Context("Invalid stemcell version", func() {
+		BeforeEach(func() {
+			setCriteria(`{"os": "ubuntu-jammy", "version": "1"}`)
+		})
+
+		It("returns an error", func() {
+			err := check("ubuntu-jammy", "latest")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`invalid stemcell version: "latest"`))
+		})
+	})
+
+	Context("Failed to get metadata", func() {
+		BeforeEach(func() {
+			metadataCmd.LoadMetadataReturns(errors.New("write metadata error"))
+		})
+
+		It("returns an error", func() {
+			err := check("ubuntu-jammy", "1.423")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("failed to load tile metadata: write metadata error"))
+		})
+	})
+})
+
+var _ = Describe("StemcellCriteria", func() {
+	It("has the major version", func() {
+		criteria := &tileinspect.StemcellCriteria{Version: "621.74"}
+		Expect(criteria.Major()).To(Equal(621))
+		Expect(criteria.IsFloating()).To(BeFalse())
+
+		criteria = &tileinspect.StemcellCriteria{Version: "1"}
+		Expect(criteria.Major()).To(Equal(1))
+		Expect(criteria.IsFloating()).To(BeTrue())
+
+		_, err := (&tileinspect.StemcellCriteria{Version: "latest"}).Major()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal(`invalid stemcell version: "latest"`))
+	})
+
+	It("enables patch security updates unless the tile turns them off", func() {
+		off := false
+		Expect((&tileinspect.StemcellCriteria{}).PatchSecurityUpdates()).To(BeTrue())
+		Expect((&tileinspect.StemcellCriteria{EnablePatchSecurityUpdates: &off}).PatchSecurityUpdates()).To(BeFalse())
+	})
+})
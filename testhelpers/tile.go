@@ -0,0 +1,59 @@
+package testhelpers
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"sort"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// TileFile is a file in a tile made by MakeTile
+type TileFile struct {
+	Name     string
+	Contents []byte
+	// Method is the compression method, zip.Store when not set
+	Method uint16
+}
+
+// FilesNamed makes a file for each name, containing "contents of <name>"
+func FilesNamed(names ...string) []TileFile {
+	files := make([]TileFile, len(names))
+	for i, name := range names {
+		files[i] = TileFile{Name: name, Contents: []byte("contents of " + name)}
+	}
+	return files
+}
+
+// MakeTile makes a tile with the given files in order, and returns its path
+func MakeTile(files ...TileFile) string {
+	tilePath := filepath.Join(GinkgoT().TempDir(), "test-tile.pivotal")
+	file, err := os.Create(tilePath)
+	Expect(err).ToNot(HaveOccurred())
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for _, tileFile := range files {
+		fileWriter, err := writer.CreateHeader(&zip.FileHeader{Name: tileFile.Name, Method: tileFile.Method})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = fileWriter.Write(tileFile.Contents)
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(writer.Close()).To(Succeed())
+
+	return tilePath
+}
+
+// MakeTileFromMap makes a tile with the given contents by file name, in order of name
+func MakeTileFromMap(contents map[string][]byte) string {
+	var files []TileFile
+	for name := range contents {
+		files = append(files, TileFile{Name: name, Contents: contents[name]})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	return MakeTile(files...)
+}
@@ -0,0 +1,198 @@
+package verify
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	tileinspect.TileConfig
+	SHA256   string `long:"sha256" description:"expected SHA-256 checksum of the tile, defaults to the checksum in <tile>.sha256 when that file exists"`
+	Parallel int    `long:"parallel" description:"number of files in the tile to check at the same time" default:"1"`
+}
+
+// Report is the outcome of verifying a tile
+type Report struct {
+	Entries int
+	// CorruptEntries are the files in the tile that fail to read or do not match their CRC-32 checksum
+	CorruptEntries []string
+	SHA256         string
+	// ExpectedSHA256 is empty when there is no checksum to compare against
+	ExpectedSHA256 string
+	ExpectedFrom   string
+}
+
+var sha256Checksum = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+func parseChecksum(checksum string, source string) (string, error) {
+	fields := strings.Fields(checksum)
+	if len(fields) == 0 || !sha256Checksum.MatchString(strings.ToLower(fields[0])) {
+		return "", errors.Errorf("the checksum in %s is not a SHA-256 checksum", source)
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// ExpectedChecksum is the checksum from --sha256, or from the sidecar file next to the tile, e.g. my-tile.pivotal.sha256
+func (cmd *Config) ExpectedChecksum() (string, string, error) {
+	if cmd.SHA256 != "" {
+		checksum, err := parseChecksum(cmd.SHA256, "--sha256")
+		return checksum, "--sha256", err
+	}
+
+	sidecar := cmd.Tile + ".sha256"
+	contents, err := os.ReadFile(sidecar)
+	if os.IsNotExist(err) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to read %s", sidecar)
+	}
+
+	checksum, err := parseChecksum(string(contents), sidecar)
+	return checksum, sidecar, err
+}
+
+func checkEntry(file *zip.File) error {
+	in, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// The zip reader checks the CRC-32 of the entry when it reaches the end
+	_, err = io.Copy(io.Discard, in)
+	return err
+}
+
+func (cmd *Config) checkEntries(files []*zip.File) []string {
+	parallel := cmd.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	failures := make([]error, len(files))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < parallel; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				failures[i] = checkEntry(files[i])
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var corrupt []string
+	for i, failure := range failures {
+		if failure != nil {
+			corrupt = append(corrupt, fmt.Sprintf("%s: %s", files[i].Name, failure.Error()))
+		}
+	}
+	return corrupt
+}
+
+func (cmd *Config) checksum() (string, error) {
+	tile, err := os.Open(cmd.Tile)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not open %s", cmd.Tile)
+	}
+	defer tile.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, tile)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not read %s", cmd.Tile)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (cmd *Config) Verify() (*Report, error) {
+	expected, expectedFrom, err := cmd.ExpectedChecksum()
+	if err != nil {
+		return nil, err
+	}
+
+	tile, err := zip.OpenReader(cmd.Tile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "the zip central directory of %s is corrupt", cmd.Tile)
+	}
+	defer tile.Close()
+
+	report := &Report{
+		Entries:        len(tile.File),
+		ExpectedSHA256: expected,
+		ExpectedFrom:   expectedFrom,
+	}
+
+	var checksumErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		report.SHA256, checksumErr = cmd.checksum()
+	}()
+	report.CorruptEntries = cmd.checkEntries(tile.File)
+	wg.Wait()
+
+	if checksumErr != nil {
+		return nil, checksumErr
+	}
+	return report, nil
+}
+
+func (report *Report) Problems() int {
+	problems := len(report.CorruptEntries)
+	if report.ExpectedSHA256 != "" && report.ExpectedSHA256 != report.SHA256 {
+		problems++
+	}
+	return problems
+}
+
+func (report *Report) Write(out io.Writer) {
+	_, _ = fmt.Fprintf(out, "OK the zip central directory has %d entries\n", report.Entries)
+	if len(report.CorruptEntries) == 0 {
+		_, _ = fmt.Fprintln(out, "OK every entry matches its CRC-32 checksum")
+	}
+	for _, entry := range report.CorruptEntries {
+		_, _ = fmt.Fprintf(out, "FAIL %s\n", entry)
+	}
+
+	_, _ = fmt.Fprintf(out, "sha256: %s\n", report.SHA256)
+	switch {
+	case report.ExpectedSHA256 == "":
+		_, _ = fmt.Fprintln(out, "no expected checksum, use --sha256 or a .sha256 file to compare against")
+	case report.ExpectedSHA256 == report.SHA256:
+		_, _ = fmt.Fprintf(out, "OK the checksum matches %s\n", report.ExpectedFrom)
+	default:
+		_, _ = fmt.Fprintf(out, "FAIL the checksum does not match %s: %s\n", report.ExpectedFrom, report.ExpectedSHA256)
+	}
+}
+
+func (cmd *Config) Execute(args []string) error {
+	report, err := cmd.Verify()
+	if err != nil {
+		return err
+	}
+
+	report.Write(os.Stdout)
+	if problems := report.Problems(); problems > 0 {
+		return errors.Errorf("the tile failed verification with %d problems", problems)
+	}
+	return nil
+}
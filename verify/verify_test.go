@@ -0,0 +1,142 @@
+package verify_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+
+	"github.com/cf-platform-eng/tileinspect"
+	"github.com/cf-platform-eng/tileinspect/testhelpers"
+	"github.com/cf-platform-eng/tileinspect/verify"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+func checksum(path string) string {
+	contents, err := os.ReadFile(path)
+	Expect(err).ToNot(HaveOccurred())
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+var _ = Describe("Verify", func() {
+	var cmd *verify.Config
+
+	BeforeEach(func() {
+		cmd = &verify.Config{
+			TileConfig: tileinspect.TileConfig{Tile: testhelpers.MakeTile(testhelpers.FilesNamed("metadata/metadata.yml", "releases/one.tgz", "releases/two.tgz")...)},
+			Parallel:   2,
+		}
+	})
+
+	It("checks every entry and computes the checksum", func() {
+		report, err := cmd.Verify()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Entries).To(Equal(3))
+		Expect(report.CorruptEntries).To(BeEmpty())
+		Expect(report.SHA256).To(Equal(checksum(cmd.Tile)))
+		Expect(report.ExpectedSHA256).To(BeEmpty())
+		Expect(report.Problems()).To(Equal(0))
+
+		buffer := NewBuffer()
+		defer buffer.Close()
+		report.Write(buffer)
+		Expect(buffer).To(Say("OK the zip central directory has 3 entries"))
+		Expect(buffer).To(Say("OK every entry matches its CRC-32 checksum"))
+		Expect(buffer).To(Say("sha256: " + report.SHA256))
+		Expect(buffer).To(Say("no expected checksum"))
+	})
+
+	Context("an entry is corrupt", func() {
+		BeforeEach(func() {
+			contents, err := os.ReadFile(cmd.Tile)
+			Expect(err).ToNot(HaveOccurred())
+			contents = bytes.Replace(contents, []byte("contents of releases/two.tgz"), []byte("CONTENTS of releases/two.tgz"), 1)
+			Expect(os.WriteFile(cmd.Tile, contents, 0644)).To(Succeed())
+		})
+
+		It("reports the entry", func() {
+			report, err := cmd.Verify()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.CorruptEntries).To(Equal([]string{"releases/two.tgz: zip: checksum error"}))
+			Expect(report.Problems()).To(Equal(1))
+
+			buffer := NewBuffer()
+			defer buffer.Close()
+			report.Write(buffer)
+			Expect(buffer).To(Say("FAIL releases/two.tgz: zip: checksum error"))
+		})
+	})
+
+	Context("the central directory is corrupt", func() {
+		BeforeEach(func() {
+			contents, err := os.ReadFile(cmd.Tile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.WriteFile(cmd.Tile, contents[:len(contents)-30], 0644)).To(Succeed())
+		})
+
+		It("returns an error", func() {
+			_, err := cmd.Verify()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("the zip central directory of " + cmd.Tile + " is corrupt"))
+		})
+	})
+
+	Context("an expected checksum is given", func() {
+		It("passes when the checksum matches", func() {
+			cmd.SHA256 = checksum(cmd.Tile)
+			report, err := cmd.Verify()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.ExpectedFrom).To(Equal("--sha256"))
+			Expect(report.Problems()).To(Equal(0))
+
+			buffer := NewBuffer()
+			defer buffer.Close()
+			report.Write(buffer)
+			Expect(buffer).To(Say("OK the checksum matches --sha256"))
+		})
+
+		It("fails when the checksum does not match", func() {
+			cmd.SHA256 = "0000000000000000000000000000000000000000000000000000000000000000"
+			report, err := cmd.Verify()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Problems()).To(Equal(1))
+
+			buffer := NewBuffer()
+			defer buffer.Close()
+			report.Write(buffer)
+			Expect(buffer).To(Say("FAIL the checksum does not match --sha256: 0000"))
+		})
+
+		It("returns an error when the checksum is invalid", func() {
+			cmd.SHA256 = "not-a-checksum"
+			_, err := cmd.Verify()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("the checksum in --sha256 is not a SHA-256 checksum"))
+		})
+	})
+
+	Context("the tile has a .sha256 file", func() {
+		It("compares against the checksum in that file", func() {
+			sidecar := cmd.Tile + ".sha256"
+			Expect(os.WriteFile(sidecar, []byte(checksum(cmd.Tile)+"  test-tile.pivotal\n"), 0644)).To(Succeed())
+
+			report, err := cmd.Verify()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.ExpectedFrom).To(Equal(sidecar))
+			Expect(report.ExpectedSHA256).To(Equal(report.SHA256))
+			Expect(report.Problems()).To(Equal(0))
+		})
+
+		It("prefers --sha256", func() {
+			Expect(os.WriteFile(cmd.Tile+".sha256", []byte("invalid"), 0644)).To(Succeed())
+			cmd.SHA256 = checksum(cmd.Tile)
+
+			report, err := cmd.Verify()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.ExpectedFrom).To(Equal("--sha256"))
+		})
+	})
+})